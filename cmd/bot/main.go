@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 
@@ -10,6 +12,9 @@ import (
 )
 
 func main() {
+	pendingMigrations := flag.Bool("pending-migrations", false, "print pending database migrations and exit")
+	flag.Parse()
+
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -19,10 +24,29 @@ func main() {
 		slog.Error("failed to load configuration", "error", err)
 		os.Exit(1)
 	}
+
+	if *pendingMigrations {
+		migrations, err := app.PendingMigrations(ctx, cfg)
+		if err != nil {
+			slog.Error("failed to check migrations", "error", err)
+			os.Exit(1)
+		}
+		if len(migrations) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		for _, m := range migrations {
+			fmt.Printf("%04d_%s\n", m.Version, m.Name)
+		}
+		return
+	}
+
 	app, err := app.NewApp(cfg)
 	if err != nil {
 		slog.Error("failed to create app", "error", err)
 		os.Exit(1)
 	}
-	app.Run(ctx)
+	if err := app.Run(ctx); err != nil {
+		slog.Error("app stopped with error", "error", err)
+		os.Exit(1)
+	}
 }
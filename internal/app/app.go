@@ -2,15 +2,26 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"spell_bot/internal/bot"
+	"spell_bot/internal/checker"
+	"spell_bot/internal/checker/cache"
 	"spell_bot/internal/config"
-	"spell_bot/internal/deepseek"
+	"spell_bot/internal/health"
+	"spell_bot/internal/llm/deepseek"
+	"spell_bot/internal/llm/openai"
+	"spell_bot/internal/metrics"
 	"spell_bot/internal/pkg/wer"
+	"spell_bot/internal/ratelimit"
 	"spell_bot/internal/storage"
+	"spell_bot/internal/storage/memory"
+	"spell_bot/internal/storage/migrate"
+	"spell_bot/internal/storage/postgres"
 	"spell_bot/internal/storage/sqlite"
 	"syscall"
 	"time"
@@ -21,6 +32,13 @@ type App struct {
 	cfg     *config.Config
 	bot     *bot.Bot
 	storage storage.Storage
+	cache   *cache.Cache
+	metrics *metrics.Metrics
+	server  *http.Server
+
+	// llmWindow - исходы последних запросов к LLM для /readyz
+	llmWindow *health.Window
+	startedAt time.Time
 }
 
 func NewApp(cfg *config.Config) (*App, error) {
@@ -29,44 +47,191 @@ func NewApp(cfg *config.Config) (*App, error) {
 	logger := initLogger(cfg.DebugMode)
 	logger.With("op", op).Info("initializing app")
 
-	sqliteStorage, err := sqlite.NewStorage(cfg.SQLitePath)
+	appMetrics := metrics.New()
+
+	rawStorage, err := newStorage(cfg)
 	if err != nil {
-		logger.Error("failed to initialize sqlite storage", "error", err)
+		logger.Error("failed to initialize storage", "error", err, "driver", cfg.StorageDriver)
 		return nil, wer.Wer(op, err)
 	}
+	appStorage := metrics.InstrumentStorage(rawStorage, appMetrics)
 
-	deepseekClient := deepseek.NewClient(cfg.DeepSeekAPIKey)
+	providerChecker, err := newCheckerRegistry(cfg, appMetrics).New(cfg.CheckerProvider)
+	if err != nil {
+		appStorage.Close()
+		logger.Error("failed to initialize checker", "error", err, "provider", cfg.CheckerProvider)
+		return nil, wer.Wer(op, err)
+	}
+	llmWindow := health.NewWindow(llmWindowSize)
+	var trackedChecker checker.Checker = health.Track(providerChecker, llmWindow)
 
-	telegramBot, err := bot.NewBot(cfg.TelegramToken, deepseekClient, sqliteStorage, logger)
+	responseCache, err := newCache(cfg, trackedChecker, checkerModel(cfg, providerChecker), appMetrics)
 	if err != nil {
-		sqliteStorage.Close()
+		appStorage.Close()
+		logger.Error("failed to initialize response cache", "error", err)
+		return nil, wer.Wer(op, err)
+	}
+	if responseCache != nil {
+		trackedChecker = responseCache
+	}
+	// Кеш стоит под разбиением на фрагменты, чтобы неизменённые абзацы
+	// отредактированного текста брались из кеша
+	textChecker := checker.NewChunked(trackedChecker, cfg.CheckerChunkSize, cfg.CheckerChunkParallelism)
+
+	limiter := ratelimit.NewLimiter(appStorage, ratelimit.Limits{
+		PerMinute:     cfg.RateLimitPerMinute,
+		Burst:         cfg.RateLimitBurst,
+		DailyRequests: cfg.DailyRequestQuota,
+		DailyChars:    cfg.DailyCharQuota,
+	}, cfg.AdminIDs)
+
+	telegramBot, err := bot.NewBot(cfg.TelegramToken, textChecker, appStorage, logger, bot.Options{
+		DiffView:  cfg.DiffView,
+		Limiter:   limiter,
+		Workers:   cfg.Workers,
+		QueueSize: cfg.QueueSize,
+		Metrics:   appMetrics,
+	})
+	if err != nil {
+		appStorage.Close()
+		responseCache.Close()
 		logger.Error("failed to initialize telegram bot", "error", err)
 		return nil, wer.Wer(op, err)
 	}
 
+	appMetrics.RegisterQueue(func() (int64, int64) {
+		stats := telegramBot.QueueStats()
+		return stats.Queued, stats.InFlight
+	})
+
 	return &App{
-		cfg:     cfg,
-		logger:  logger,
-		bot:     telegramBot,
-		storage: sqliteStorage,
+		cfg:       cfg,
+		logger:    logger,
+		bot:       telegramBot,
+		storage:   appStorage,
+		cache:     responseCache,
+		metrics:   appMetrics,
+		llmWindow: llmWindow,
 	}, nil
 }
 
+// newStorage создаёт хранилище выбранного в конфигурации бэкенда
+func newStorage(cfg *config.Config) (storage.Storage, error) {
+	switch cfg.StorageDriver {
+	case "sqlite":
+		return sqlite.NewStorage(cfg.SQLitePath)
+	case "memory":
+		return memory.NewStorage(), nil
+	case "postgres":
+		return postgres.NewStorage(cfg.PostgresDSN, postgres.PoolConfig{
+			MaxOpenConns:    cfg.PostgresMaxOpenConns,
+			MaxIdleConns:    cfg.PostgresMaxIdleConns,
+			ConnMaxLifetime: cfg.PostgresConnMaxLifetime,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
+
+// newCache создаёт кеш ответов перед checker; nil, если все уровни отключены
+func newCache(cfg *config.Config, inner checker.Checker, model string, m *metrics.Metrics) (*cache.Cache, error) {
+	var tiers []cache.Tier
+	if cfg.CheckerCacheSize > 0 {
+		tiers = append(tiers, cache.NewLRU(cfg.CheckerCacheSize))
+	}
+	if cfg.CheckerCacheSQLitePath != "" {
+		persistent, err := cache.NewSQLite(cfg.CheckerCacheSQLitePath)
+		if err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, persistent)
+	}
+	if len(tiers) == 0 {
+		return nil, nil
+	}
+
+	// Ответы разных моделей и версий промпта не смешиваются
+	version := fmt.Sprintf("%s:%s:%s", cfg.CheckerProvider, model, openai.PromptVersion)
+
+	return cache.New(inner, version, cfg.CheckerCacheTTL, m, tiers...), nil
+}
+
+// checkerModel возвращает имя модели провайдера, если он его сообщает
+func checkerModel(cfg *config.Config, provider checker.Checker) string {
+	if named, ok := provider.(interface{ Model() string }); ok {
+		return named.Model()
+	}
+	return cfg.CheckerProvider
+}
+
+// PendingMigrations возвращает миграции, ещё не применённые к настроенной БД
+func PendingMigrations(ctx context.Context, cfg *config.Config) ([]migrate.Migration, error) {
+	switch cfg.StorageDriver {
+	case "sqlite":
+		return sqlite.PendingMigrations(ctx, cfg.SQLitePath)
+	case "postgres":
+		return postgres.PendingMigrations(ctx, cfg.PostgresDSN)
+	case "memory":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
+
+// newCheckerRegistry регистрирует все доступные провайдеры проверки текста
+func newCheckerRegistry(cfg *config.Config, m *metrics.Metrics) *checker.Registry {
+	registry := checker.NewRegistry()
+
+	retry := openai.WithRetryPolicy(openai.RetryPolicy{
+		MaxAttempts: cfg.CheckerMaxAttempts,
+		BaseDelay:   cfg.CheckerRetryBaseDelay,
+		MaxDelay:    cfg.CheckerRetryMaxDelay,
+	})
+
+	breaker := func() openai.Option {
+		return openai.WithCircuitBreaker(openai.NewCircuitBreaker(cfg.CheckerBreakerThreshold, cfg.CheckerBreakerCooldown))
+	}
+
+	registry.Register("deepseek", func() (checker.Checker, error) {
+		return deepseek.NewClient(cfg.DeepSeekAPIKey, openai.WithBaseURL(cfg.DeepSeekBaseURL), retry, breaker(), openai.WithMetrics(m)), nil
+	})
+
+	// Любой OpenAI-совместимый /chat/completions эндпоинт
+	registry.Register("openai", func() (checker.Checker, error) {
+		if cfg.OpenAIBaseURL == "" || cfg.OpenAIModel == "" {
+			return nil, fmt.Errorf("OPENAI_BASE_URL and OPENAI_MODEL are required")
+		}
+		return openai.NewClient(
+			cfg.OpenAIAPIKey,
+			openai.WithBaseURL(cfg.OpenAIBaseURL),
+			openai.WithModel(cfg.OpenAIModel),
+			openai.WithHeaders(cfg.OpenAIHeaders),
+			retry,
+			breaker(),
+			openai.WithMetrics(m),
+		), nil
+	})
+
+	return registry
+}
+
 func (a *App) gracefulShutdown() {
 	a.logger.Info("shutting down gracefully")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), a.cfg.ShutdownTimeout)
 	defer cancel()
 
-	// Останавливаем бота
-	a.bot.Stop()
+	// Останавливаем приём обновлений и ждём завершения текущих проверок
+	if err := a.bot.Stop(ctx); err != nil {
+		a.logger.Error("bot did not drain in time", "error", err)
+	}
+	a.stopHTTPServer(ctx)
 	if err := a.storage.Close(); err != nil {
 		a.logger.Error("failed to close storage", "error", err)
 	}
-
-	// Ждём завершения обработки текущих сообщений
-	// или таймаут
-	<-ctx.Done()
+	if err := a.cache.Close(); err != nil {
+		a.logger.Error("failed to close response cache", "error", err)
+	}
 
 	a.logger.Info("shutdown complete")
 }
@@ -83,29 +248,54 @@ func initLogger(debugMode bool) *slog.Logger {
 	return logger
 }
 
-func (a *App) Run(ctx context.Context) {
+// Run запускает бота и ждёт сигнала остановки. Возвращает ошибку, если цикл
+// получения обновлений или HTTP-сервер завершились сами.
+func (a *App) Run(ctx context.Context) error {
 	logger := a.logger.With("app", "spell_bot")
+	a.startedAt = time.Now()
+
+	errCh := make(chan error, 2)
+	a.startHTTPServer(errCh)
 
 	go func() {
-		err := a.initBot(ctx)
-		if err != nil {
-			logger.Error("failed to initialize bot", "error", err)
-			return
-		}
+		errCh <- a.initBot(ctx)
 	}()
 
 	logger.Info("app started")
 
 	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
-	<-signalCh
+
+	var err error
+	select {
+	case <-signalCh:
+	case err = <-errCh:
+		if err == nil {
+			err = errors.New("bot loop stopped unexpectedly")
+		}
+		logger.Error("bot stopped, shutting down", "error", err)
+	}
 
 	a.gracefulShutdown()
+	return err
 }
 
 func (a *App) initBot(ctx context.Context) error {
-	if err := a.bot.Start(ctx); err != nil {
-		a.logger.Error("bot stopped with error", "error", err)
+	var err error
+	switch a.cfg.BotMode {
+	case "polling":
+		err = a.bot.Start(ctx)
+	case "webhook":
+		err = a.bot.StartWebhook(ctx, bot.WebhookConfig{
+			URL:        a.cfg.WebhookURL,
+			ListenAddr: a.cfg.WebhookListenAddr,
+			Path:       a.cfg.WebhookPath,
+			Secret:     a.cfg.WebhookSecret,
+		})
+	default:
+		err = fmt.Errorf("unknown bot mode %q", a.cfg.BotMode)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to start bot: %w", err)
 	}
 	return nil
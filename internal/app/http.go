@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"spell_bot/internal/health"
+)
+
+const (
+	// llmWindowSize - число последних запросов к LLM, по которым считается доля успешных
+	llmWindowSize = 50
+	// llmMinSamples - меньше запросов недостаточно, чтобы признать LLM недоступной
+	llmMinSamples = 5
+
+	probeTimeout = 3 * time.Second
+)
+
+// startHTTPServer запускает служебный HTTP-сервер с /metrics, /healthz и /readyz.
+// Ошибка запуска передаётся в errCh.
+func (a *App) startHTTPServer(errCh chan<- error) {
+	if a.cfg.MetricsAddr == "" {
+		return
+	}
+
+	// Webhook-запросы приходят только при наличии обновлений, поэтому
+	// давность последнего getUpdates проверяется лишь в режиме polling
+	pollStaleAfter := a.cfg.HealthPollStaleAfter
+	if a.cfg.BotMode != "polling" {
+		pollStaleAfter = 0
+	}
+	probeBot := health.LoopProbe(a.bot, a.cfg.BotMode, a.startedAt, pollStaleAfter)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", a.metrics.Handler())
+	mux.Handle("/healthz", health.Handler(probeTimeout, map[string]health.Probe{
+		"bot": probeBot,
+	}))
+	mux.Handle("/readyz", health.Handler(probeTimeout, map[string]health.Probe{
+		"bot":     probeBot,
+		"storage": a.probeStorage,
+		"llm":     health.SuccessRateProbe(a.llmWindow, a.cfg.HealthMinSuccessRate, llmMinSamples),
+	}))
+
+	a.server = &http.Server{
+		Addr:              a.cfg.MetricsAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		a.logger.Info("http server started", "addr", a.cfg.MetricsAddr)
+		if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- fmt.Errorf("http server failed: %w", err)
+		}
+	}()
+}
+
+func (a *App) stopHTTPServer(ctx context.Context) {
+	if a.server == nil {
+		return
+	}
+	if err := a.server.Shutdown(ctx); err != nil {
+		a.logger.Error("failed to stop http server", "error", err)
+	}
+}
+
+func (a *App) probeStorage(ctx context.Context) health.Result {
+	if err := a.storage.Ping(ctx); err != nil {
+		return health.Result{Error: err.Error()}
+	}
+	return health.Result{OK: true}
+}
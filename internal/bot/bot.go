@@ -2,72 +2,264 @@ package bot
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"spell_bot/internal/deepseek"
+	"spell_bot/internal/checker"
+	"spell_bot/internal/metrics"
+	"spell_bot/internal/ratelimit"
 	"spell_bot/internal/storage"
+	"spell_bot/internal/workerpool"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// Options - необязательные настройки поведения бота
+type Options struct {
+	// DiffView - подсветка изменений по умолчанию для пользователей без сохранённых настроек
+	DiffView bool
+	// Limiter ограничивает частоту и дневной объём проверок; nil отключает ограничения
+	Limiter *ratelimit.Limiter
+
+	// Workers - число воркеров, обрабатывающих обновления
+	Workers int
+	// QueueSize - ёмкость очереди обновлений; при заполнении приём обновлений приостанавливается
+	QueueSize int
+
+	// Metrics - метрики Prometheus; nil отключает их
+	Metrics *metrics.Metrics
+}
+
 type Bot struct {
 	api     *tgbotapi.BotAPI
 	handler *Handler
 	logger  *slog.Logger
+	pool    *workerpool.Pool
+	// inline откладывает inline-запросы до паузы в наборе, не занимая воркеры
+	inline *debouncer
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	started  atomic.Bool
+	loopDone chan struct{}
+	lastPoll atomic.Int64 // UnixNano
 }
 
-func NewBot(token string, deepseekClient *deepseek.Client, storage storage.Storage, logger *slog.Logger) (*Bot, error) {
+func NewBot(token string, checker checker.Checker, storage storage.Storage, logger *slog.Logger, opts Options) (*Bot, error) {
 	api, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create handler with the bot API
-	handler := NewHandler(api, deepseekClient, storage, logger)
+	handler := NewHandler(api, checker, storage, logger, opts)
 
 	bot := &Bot{
-		api:     api,
-		handler: handler,
-		logger:  logger,
+		api:      api,
+		handler:  handler,
+		logger:   logger,
+		pool:     workerpool.New(opts.Workers, opts.QueueSize, logger),
+		inline:   newDebouncer(),
+		stopCh:   make(chan struct{}),
+		loopDone: make(chan struct{}),
 	}
 
 	bot.logger.Info("bot initialized", "username", bot.api.Self.UserName)
 	return bot, nil
 }
 
+// pollRetryDelay - пауза после неудачного запроса getUpdates
+const pollRetryDelay = 3 * time.Second
+
+// Start получает обновления long polling и передаёт их в пул воркеров.
+// Обновления одного чата обрабатываются последовательно.
 func (b *Bot) Start(ctx context.Context) error {
-	b.logger.Info("starting bot")
+	const op = "bot.Start"
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
+	b.logger.Info("starting bot", "mode", "polling")
+	b.started.Store(true)
+	defer close(b.loopDone)
 
-	updates := b.api.GetUpdatesChan(u)
+	ctx, cancel := b.runContext(ctx)
+	defer cancel()
+
+	// Пока зарегистрирован webhook, getUpdates отвечает 409 Conflict
+	if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return fmt.Errorf("%s: failed to delete webhook: %w", op, err)
+	}
+
+	updates := make(chan tgbotapi.Update)
+	go b.poll(ctx, updates)
+
+	go b.handler.runPending(ctx)
 
 	for {
 		select {
 		case <-ctx.Done():
-			b.logger.Info("stopping bot")
-			b.api.StopReceivingUpdates()
 			return nil
 
 		case update := <-updates:
-			go b.handleUpdate(ctx, update)
+			if err := b.dispatch(ctx, update); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// poll запрашивает getUpdates и запоминает время последнего успешного ответа.
+// Запрос long polling не прерывается отменой ctx, поэтому poll работает
+// в отдельной горутине и завершается после ответа Telegram.
+func (b *Bot) poll(ctx context.Context, out chan<- tgbotapi.Update) {
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+
+	for ctx.Err() == nil {
+		updates, err := b.api.GetUpdates(u)
+		if err != nil {
+			b.logger.Warn("failed to get updates", "error", err, "retry_in", pollRetryDelay)
+			select {
+			case <-time.After(pollRetryDelay):
+			case <-ctx.Done():
+			}
+			continue
+		}
+		b.lastPoll.Store(time.Now().UnixNano())
+
+		for _, update := range updates {
+			if update.UpdateID < u.Offset {
+				continue
+			}
+			select {
+			case out <- update:
+				u.Offset = update.UpdateID + 1
+			case <-ctx.Done():
+				return
+			}
 		}
 	}
 }
 
+// LastPoll возвращает время последнего успешного getUpdates или запроса
+// webhook; нулевое время, если их ещё не было
+func (b *Bot) LastPoll() time.Time {
+	ns := b.lastPoll.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// Running сообщает, работает ли цикл получения обновлений
+func (b *Bot) Running() bool {
+	if !b.started.Load() {
+		return false
+	}
+	select {
+	case <-b.loopDone:
+		return false
+	default:
+		return true
+	}
+}
+
+// runContext возвращает ctx, который отменяется вызовом Stop.
+// Отмена разблокирует Submit при заполненной очереди.
+func (b *Bot) runContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-b.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// dispatch ставит обновление в очередь чата; блокируется, пока очередь заполнена.
+// Inline-запрос попадает в очередь только после паузы в наборе, и лишь последний.
+func (b *Bot) dispatch(ctx context.Context, update tgbotapi.Update) error {
+	b.handler.opts.Metrics.UpdateReceived(updateType(update))
+
+	if query := update.InlineQuery; query != nil && query.From != nil {
+		b.inline.debounce(query.From.ID, inlineDebounce, func() {
+			// Запрос webhook к этому моменту завершён, поэтому ждём места
+			// в очереди до остановки бота
+			ctx, cancel := b.runContext(context.Background())
+			defer cancel()
+
+			if err := b.submit(ctx, update); err != nil {
+				b.logger.Warn("inline query dropped", "update_id", update.UpdateID, "error", err)
+			}
+		})
+		return nil
+	}
+
+	return b.submit(ctx, update)
+}
+
+func (b *Bot) submit(ctx context.Context, update tgbotapi.Update) error {
+	return b.pool.Submit(ctx, updateKey(update), func(taskCtx context.Context) {
+		b.handleUpdate(taskCtx, update)
+	})
+}
+
 func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
 	start := time.Now()
 	b.handler.HandleUpdate(ctx, update)
 	duration := time.Since(start)
 
+	stats := b.pool.Stats()
 	b.logger.Debug("update processed",
 		"update_id", update.UpdateID,
 		"duration_ms", duration.Milliseconds(),
+		"queue_depth", stats.Queued,
+		"in_flight", stats.InFlight,
 	)
 }
 
+// QueueStats возвращает загрузку очереди обновлений
+func (b *Bot) QueueStats() workerpool.Stats {
+	return b.pool.Stats()
+}
+
+// updateKey выбирает ключ очереди: обновления одного чата обрабатываются по порядку.
+// Inline-запросы порядка не требуют и распределяются по всем воркерам, чтобы
+// не ждать сообщений, стоящих в очереди личного чата пользователя.
+func updateKey(update tgbotapi.Update) int64 {
+	if update.InlineQuery != nil {
+		return int64(update.UpdateID)
+	}
+	if chat := update.FromChat(); chat != nil {
+		return chat.ID
+	}
+	if user := update.SentFrom(); user != nil {
+		return user.ID
+	}
+	return int64(update.UpdateID)
+}
+
+// updateType возвращает тип обновления для метрик
+func updateType(update tgbotapi.Update) string {
+	switch {
+	case update.Message != nil:
+		return "message"
+	case update.EditedMessage != nil:
+		return "edited_message"
+	case update.CallbackQuery != nil:
+		return "callback_query"
+	case update.InlineQuery != nil:
+		return "inline_query"
+	case update.ChannelPost != nil:
+		return "channel_post"
+	default:
+		return "other"
+	}
+}
+
 func (b *Bot) API() *tgbotapi.BotAPI {
 	return b.api
 }
@@ -76,7 +268,23 @@ func (b *Bot) GetUsername() string {
 	return b.api.Self.UserName
 }
 
-func (b *Bot) Stop() {
+// Stop прекращает приём обновлений и ждёт обработки уже полученных,
+// но не дольше, чем позволяет ctx
+func (b *Bot) Stop(ctx context.Context) error {
 	b.logger.Info("stopping bot")
-	b.api.StopReceivingUpdates()
+
+	b.stopOnce.Do(func() { close(b.stopCh) })
+	b.inline.stop()
+
+	if b.started.Load() {
+		select {
+		case <-b.loopDone:
+		case <-ctx.Done():
+		}
+	}
+
+	stats := b.pool.Stats()
+	b.logger.Info("draining update queue", "queued", stats.Queued, "in_flight", stats.InFlight)
+
+	return b.pool.Drain(ctx)
 }
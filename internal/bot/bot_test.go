@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"spell_bot/internal/storage/memory"
+	"spell_bot/internal/workerpool"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// telegramCall - запрос бота к Bot API
+type telegramCall struct {
+	method string
+	params map[string]string
+}
+
+// telegramStub - заглушка Bot API, запоминающая вызовы методов
+type telegramStub struct {
+	mu    sync.Mutex
+	calls []telegramCall
+}
+
+func (s *telegramStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	r.ParseMultipartForm(1 << 20)
+	params := make(map[string]string)
+	for k := range r.Form {
+		params[k] = r.Form.Get(k)
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, telegramCall{method: method, params: params})
+	s.mu.Unlock()
+
+	var result any = true
+	switch method {
+	case "getMe":
+		result = tgbotapi.User{ID: 1, IsBot: true, UserName: "spell_test_bot"}
+	case "getUpdates":
+		// Имитация long polling без обновлений
+		time.Sleep(20 * time.Millisecond)
+		result = []tgbotapi.Update{}
+	case "sendMessage":
+		result = tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: 1}}
+	}
+
+	raw, _ := json.Marshal(result)
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: raw})
+}
+
+// methods возвращает имена вызванных методов по порядку
+func (s *telegramStub) methods() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	methods := make([]string, len(s.calls))
+	for i, c := range s.calls {
+		methods[i] = c.method
+	}
+	return methods
+}
+
+// find возвращает первый вызов метода method
+func (s *telegramStub) find(method string) (telegramCall, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.calls {
+		if c.method == method {
+			return c, true
+		}
+	}
+	return telegramCall{}, false
+}
+
+// testLogger не выводит логи тестов
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newTestAPI создаёт клиент Bot API, который обращается к заглушке
+func newTestAPI(t *testing.T) (*tgbotapi.BotAPI, *telegramStub) {
+	t.Helper()
+
+	stub := &telegramStub{}
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+
+	api, err := tgbotapi.NewBotAPIWithClient("test-token", srv.URL+"/bot%s/%s", srv.Client())
+	if err != nil {
+		t.Fatalf("NewBotAPIWithClient: %v", err)
+	}
+	return api, stub
+}
+
+// newTestBot создаёт бота, который обращается к заглушке Bot API
+func newTestBot(t *testing.T) (*Bot, *telegramStub) {
+	t.Helper()
+
+	api, stub := newTestAPI(t)
+	b := &Bot{
+		api:      api,
+		handler:  NewHandler(api, nil, memory.NewStorage(), testLogger, Options{}),
+		logger:   testLogger,
+		pool:     workerpool.New(1, 10, testLogger),
+		inline:   newDebouncer(),
+		stopCh:   make(chan struct{}),
+		loopDone: make(chan struct{}),
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		b.Stop(ctx)
+	})
+
+	return b, stub
+}
+
+func TestStartDeletesWebhookBeforePolling(t *testing.T) {
+	b, stub := newTestBot(t)
+
+	done := make(chan error, 1)
+	go func() { done <- b.Start(context.Background()) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := stub.find("getUpdates"); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("getUpdates was not called, calls: %v", stub.methods())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := b.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	var deleted bool
+	for _, method := range stub.methods() {
+		if method == "deleteWebhook" {
+			deleted = true
+		}
+		if method == "getUpdates" {
+			break
+		}
+	}
+	if !deleted {
+		t.Errorf("deleteWebhook was not called before getUpdates, calls: %v", stub.methods())
+	}
+}
@@ -0,0 +1,197 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackCaption = "caption:"
+
+	// captionLimit - максимальная длина подписи Telegram в UTF-16 единицах
+	captionLimit = 1024
+	// captionStoreLimit ограничивает число исправленных подписей, ожидающих нажатия кнопки
+	captionStoreLimit = 1000
+	// captionTTL - время, в течение которого можно получить копию медиа
+	captionTTL = 24 * time.Hour
+)
+
+// messageText возвращает текст сообщения или подпись к медиа вместе с сущностями
+func messageText(msg *tgbotapi.Message) (string, []tgbotapi.MessageEntity) {
+	if msg.Text != "" {
+		return msg.Text, msg.Entities
+	}
+	return msg.Caption, msg.CaptionEntities
+}
+
+// hasMedia сообщает, есть ли в сообщении медиа, которое можно отправить с подписью
+func hasMedia(msg *tgbotapi.Message) bool {
+	return len(msg.Photo) > 0 || msg.Video != nil || msg.Animation != nil ||
+		msg.Document != nil || msg.Audio != nil || msg.Voice != nil
+}
+
+// forwardOrigin описывает источник пересланного сообщения; пустая строка,
+// если сообщение не переслано
+func forwardOrigin(msg *tgbotapi.Message) string {
+	var origin string
+	switch {
+	case msg.ForwardFromChat != nil:
+		origin = msg.ForwardFromChat.Title
+		if msg.ForwardFromChat.UserName != "" {
+			origin += " (@" + msg.ForwardFromChat.UserName + ")"
+		}
+		if msg.ForwardSignature != "" {
+			origin += ", " + msg.ForwardSignature
+		}
+	case msg.ForwardFrom != nil:
+		origin = strings.TrimSpace(msg.ForwardFrom.FirstName + " " + msg.ForwardFrom.LastName)
+		if msg.ForwardFrom.UserName != "" {
+			origin += " (@" + msg.ForwardFrom.UserName + ")"
+		}
+	case msg.ForwardSenderName != "":
+		// Отправитель скрыл ссылку на свой аккаунт
+		origin = msg.ForwardSenderName
+	case msg.ForwardDate != 0:
+		origin = "скрытого отправителя"
+	default:
+		return ""
+	}
+
+	if msg.ForwardDate != 0 {
+		origin += ", " + time.Unix(int64(msg.ForwardDate), 0).UTC().Format("02.01.2006 15:04 UTC")
+	}
+	return origin
+}
+
+// sourceHeader описывает, откуда взят проверенный текст: пересланное сообщение или подпись
+func sourceHeader(req checkRequest) string {
+	var b strings.Builder
+	if req.origin != "" {
+		b.WriteString("↪️ Пересланное сообщение от <b>" + html.EscapeString(req.origin) + "</b>\n")
+	}
+	if req.media != 0 {
+		b.WriteString("🖼 Проверена подпись к медиа\n")
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// captionCopy - исправленная подпись к медиа, которую можно получить кнопкой
+type captionCopy struct {
+	telegramID int64
+	caption    string // Telegram HTML
+	created    time.Time
+}
+
+type captionKey struct {
+	chatID    int64
+	messageID int
+}
+
+// captionStore хранит исправленные подписи до нажатия кнопки; при переполнении
+// вытесняются самые старые
+type captionStore struct {
+	mu    sync.Mutex
+	items map[captionKey]captionCopy
+	limit int
+	ttl   time.Duration
+}
+
+func newCaptionStore(limit int, ttl time.Duration) *captionStore {
+	return &captionStore{items: make(map[captionKey]captionCopy), limit: limit, ttl: ttl}
+}
+
+func (s *captionStore) put(key captionKey, c captionCopy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[key]; !ok && len(s.items) >= s.limit {
+		var (
+			oldest captionKey
+			found  bool
+		)
+		for k, item := range s.items {
+			if !found || item.created.Before(s.items[oldest].created) {
+				oldest, found = k, true
+			}
+		}
+		delete(s.items, oldest)
+	}
+	s.items[key] = c
+}
+
+func (s *captionStore) get(key captionKey) (captionCopy, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.items[key]
+	if ok && time.Since(c.created) > s.ttl {
+		delete(s.items, key)
+		return captionCopy{}, false
+	}
+	return c, ok
+}
+
+// captionKeyboard запоминает исправленную подпись и возвращает кнопку для
+// получения копии медиа с ней; nil, если подпись не помещается в лимит Telegram
+func (h *Handler) captionKeyboard(req checkRequest, corrected string) *tgbotapi.InlineKeyboardMarkup {
+	if len(utf16.Encode([]rune(corrected))) > captionLimit {
+		return nil
+	}
+
+	caption := html.EscapeString(corrected)
+	if req.formatted != "" {
+		caption = req.formatted
+	}
+	h.captions.put(captionKey{chatID: req.chatID, messageID: req.media}, captionCopy{
+		telegramID: req.telegramID,
+		caption:    caption,
+		created:    time.Now(),
+	})
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📎 Копия с исправленной подписью", fmt.Sprintf("%s%d", callbackCaption, req.media)),
+	))
+	return &markup
+}
+
+// handleCaptionCallback отправляет копию медиа с исправленной подписью,
+// готовую к пересылке
+func (h *Handler) handleCaptionCallback(query *tgbotapi.CallbackQuery) {
+	messageID, err := strconv.Atoi(strings.TrimPrefix(query.Data, callbackCaption))
+	if err != nil || query.Message == nil {
+		h.answerCallback(query.ID, "")
+		return
+	}
+	chatID := query.Message.Chat.ID
+
+	c, ok := h.captions.get(captionKey{chatID: chatID, messageID: messageID})
+	if !ok {
+		h.answerCallback(query.ID, "Подпись устарела. Отправьте медиа ещё раз.")
+		return
+	}
+	if c.telegramID != query.From.ID {
+		h.answerCallback(query.ID, "Копию может получить только тот, кто запросил проверку.")
+		return
+	}
+
+	copyMsg := tgbotapi.NewCopyMessage(chatID, chatID, messageID)
+	copyMsg.Caption = c.caption
+	copyMsg.ParseMode = "HTML"
+	if _, err := h.bot.CopyMessage(copyMsg); err != nil {
+		h.logger.Error("failed to copy message", "error", err, "chat_id", chatID)
+		h.answerCallback(query.ID, "Не удалось отправить копию. Возможно, исходное сообщение удалено.")
+		return
+	}
+
+	h.answerCallback(query.ID, "")
+}
@@ -0,0 +1,212 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"spell_bot/internal/checker"
+	"spell_bot/internal/pkg/document"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// maxDocumentSize ограничивает размер скачиваемого файла
+	maxDocumentSize = 2 << 20
+	// maxDocumentChars ограничивает объём текста документа
+	maxDocumentChars = 50000
+	// documentDownloadTimeout ограничивает скачивание файла с серверов Telegram
+	documentDownloadTimeout = 30 * time.Second
+)
+
+// categoryOrder - порядок категорий в сводке по документу
+var categoryOrder = []string{
+	checker.CategorySpelling,
+	checker.CategoryPunctuation,
+	checker.CategoryGrammar,
+	checker.CategoryStyle,
+	checker.CategoryOther,
+}
+
+// isDocumentCheck сообщает, проверяется ли сообщение как документ. У файла
+// неподдерживаемого формата проверяется подпись, если она есть.
+func isDocumentCheck(msg *tgbotapi.Message) bool {
+	if msg.Document == nil {
+		return false
+	}
+	_, ok := document.FormatOf(msg.Document.FileName)
+	return ok || msg.Caption == ""
+}
+
+// handleDocument проверяет текст файла и отвечает исправленным файлом того же
+// формата и сводкой исправлений. req задаёт чат, автора и сообщение для ответа.
+func (h *Handler) handleDocument(ctx context.Context, file *tgbotapi.Document, req checkRequest) {
+	chatID := req.chatID
+
+	format, ok := document.FormatOf(file.FileName)
+	if !ok {
+		h.sendReply(chatID, req.replyTo, "📄 Поддерживаются документы .txt, .md, .docx и .odt.")
+		return
+	}
+	if file.FileSize > maxDocumentSize {
+		h.sendReply(chatID, req.replyTo, fmt.Sprintf("📄 Файл слишком большой. Максимальный размер - %d МБ.", maxDocumentSize>>20))
+		return
+	}
+
+	h.sendChatAction(chatID, tgbotapi.ChatTyping)
+
+	data, err := h.downloadFile(ctx, file.FileID)
+	if err != nil {
+		h.logger.Error("failed to download document", "error", err, "chat_id", chatID)
+		h.sendReply(chatID, req.replyTo, "❌ Не удалось скачать файл. Пожалуйста, попробуйте позже.")
+		return
+	}
+
+	doc, err := document.Parse(format, data)
+	if err != nil {
+		h.logger.Warn("failed to parse document", "error", err, "chat_id", chatID, "format", format)
+		h.sendReply(chatID, req.replyTo, "❌ Не удалось прочитать файл. Проверьте, что он не повреждён.")
+		return
+	}
+
+	req.text = doc.Text()
+	if strings.TrimSpace(req.text) == "" {
+		h.sendReply(chatID, req.replyTo, "📄 В документе нет текста для проверки.")
+		return
+	}
+	if utf8.RuneCountInString(req.text) > maxDocumentChars {
+		h.sendReply(chatID, req.replyTo, fmt.Sprintf("📄 Документ слишком длинный. Максимум - %d символов.", maxDocumentChars))
+		return
+	}
+
+	if !h.allowCheck(ctx, req) {
+		return
+	}
+	req.settings = h.userSettings(ctx, req.telegramID)
+
+	h.logger.Info("processing document check", "chat_id", chatID, "format", format, "text_length", len(req.text))
+	h.sendChatAction(chatID, tgbotapi.ChatUploadDocument)
+
+	// Документ не откладывается в очередь: файл пришлось бы хранить до восстановления
+	response, err := h.check(ctx, &req)
+	if errors.Is(err, checker.ErrUnavailable) {
+		h.sendReply(chatID, req.replyTo, "⏳ Проверка временно недоступна. Пожалуйста, отправьте файл позже.")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to check document", "error", err, "chat_id", chatID)
+		h.sendReply(chatID, req.replyTo, "❌ Произошла ошибка при проверке документа. Пожалуйста, попробуйте позже.")
+		return
+	}
+
+	if !response.HasChanges {
+		h.sendReply(chatID, req.replyTo, "✅ <b>Документ проверен и не требует исправлений!</b>")
+		return
+	}
+
+	corrected, err := doc.Apply(response.CorrectedText)
+	if err != nil {
+		h.logger.Error("failed to build corrected document", "error", err, "chat_id", chatID, "format", format)
+		h.sendReply(chatID, req.replyTo, "❌ Не удалось собрать исправленный файл. Пожалуйста, попробуйте позже.")
+		return
+	}
+
+	upload := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: correctedFileName(file.FileName), Bytes: corrected})
+	upload.ReplyToMessageID = req.replyTo
+	upload.AllowSendingWithoutReply = true
+	if _, err := h.bot.Send(upload); err != nil {
+		h.logger.Error("failed to send document", "error", err, "chat_id", chatID)
+		h.sendReply(chatID, req.replyTo, "❌ Не удалось отправить исправленный файл. Пожалуйста, попробуйте позже.")
+		return
+	}
+
+	h.sendReply(chatID, req.replyTo, h.documentSummary(response, req))
+}
+
+// documentSummary описывает исправления в документе: число правок по категориям
+// и, если пользователь включил объяснения, их список
+func (h *Handler) documentSummary(response *checker.CheckResponse, req checkRequest) string {
+	var b strings.Builder
+	b.WriteString("✏️ <b>Документ исправлен!</b>")
+
+	if len(response.Edits) == 0 {
+		if req.settings.ShowExplanations && response.Explanation != "" {
+			b.WriteString("\n\n💡 <b>Исправления:</b>\n")
+			b.WriteString(h.escapeHTML(response.Explanation))
+		}
+		return b.String()
+	}
+
+	counts := make(map[string]int)
+	for _, e := range response.Edits {
+		counts[e.Category]++
+	}
+	b.WriteString(fmt.Sprintf("\n\n📊 <b>Исправлений: %d</b>", len(response.Edits)))
+	for _, category := range categoryOrder {
+		if counts[category] > 0 {
+			b.WriteString(fmt.Sprintf("\n• %s: %d", categoryNames[category], counts[category]))
+		}
+	}
+
+	if req.settings.ShowExplanations {
+		b.WriteString("\n\n💡 <b>Исправления:</b>\n")
+		b.WriteString(h.formatEdits(response.Edits))
+	}
+
+	return b.String()
+}
+
+// downloadFile скачивает файл с серверов Telegram
+func (h *Handler) downloadFile(ctx context.Context, fileID string) ([]byte, error) {
+	link, err := h.bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, documentDownloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := h.bot.Client.Do(req)
+	if err != nil {
+		// Ссылка на файл содержит токен бота и не должна попасть в логи
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > maxDocumentSize {
+		return nil, fmt.Errorf("file exceeds %d bytes", maxDocumentSize)
+	}
+
+	return data, nil
+}
+
+// correctedFileName добавляет к имени файла пометку об исправлении
+func correctedFileName(name string) string {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "_corrected" + ext
+}
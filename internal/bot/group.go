@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"spell_bot/internal/entity"
+	"spell_bot/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func isGroupChat(chat *tgbotapi.Chat) bool {
+	return chat != nil && (chat.IsGroup() || chat.IsSuperGroup())
+}
+
+// handleGroupMessage реагирует в группе только на упоминание бота, /check
+// в ответ на сообщение и, если включена автопроверка, на сообщения участников.
+// Сообщения других ботов и каналов, служебные сообщения игнорируются.
+func (h *Handler) handleGroupMessage(ctx context.Context, msg *tgbotapi.Message) {
+	// Анонимный администратор пишет от имени самой группы
+	anonymousAdmin := msg.SenderChat != nil && msg.SenderChat.ID == msg.Chat.ID
+	text, _ := messageText(msg)
+	if msg.From == nil || text == "" {
+		return
+	}
+	if !anonymousAdmin && (msg.From.IsBot || msg.SenderChat != nil) {
+		return
+	}
+
+	if msg.IsCommand() {
+		if !h.addressedToBot(msg) {
+			return
+		}
+		switch msg.Command() {
+		case "check":
+			h.handleGroupCheckCommand(ctx, msg)
+		case "autocheck":
+			h.handleAutoCheckCommand(ctx, msg, anonymousAdmin)
+		case "history":
+			// История содержит тексты пользователя и не должна попадать в общий чат
+			h.sendReply(msg.Chat.ID, msg.MessageID, "📜 История проверок доступна в личном чате со мной.")
+		default:
+			h.handleCommand(ctx, msg)
+		}
+		return
+	}
+
+	if stripped, mentioned := h.stripMention(text); mentioned {
+		var target string
+		if msg.ReplyToMessage != nil {
+			target, _ = messageText(msg.ReplyToMessage)
+		}
+		switch {
+		case stripped != "":
+			h.runCheck(ctx, groupCheckRequest(msg, msg, stripped, false))
+		case target != "":
+			h.runCheck(ctx, groupCheckRequest(msg, msg.ReplyToMessage, target, false))
+		default:
+			h.sendReply(msg.Chat.ID, msg.MessageID, "Напишите текст после упоминания или упомяните меня в ответ на сообщение, которое нужно проверить.")
+		}
+		return
+	}
+
+	if h.chatSettings(ctx, msg.Chat.ID).AutoCheck {
+		h.runCheck(ctx, groupCheckRequest(msg, msg, text, true))
+	}
+}
+
+// handleGroupCheckCommand проверяет сообщение или документ, на который
+// ответили /check, или текст после команды
+func (h *Handler) handleGroupCheckCommand(ctx context.Context, msg *tgbotapi.Message) {
+	if target := msg.ReplyToMessage; target != nil && isDocumentCheck(target) {
+		h.handleDocument(ctx, target.Document, groupCheckRequest(msg, target, "", false))
+		return
+	}
+	if target := msg.ReplyToMessage; target != nil {
+		if text, _ := messageText(target); text != "" {
+			h.runCheck(ctx, groupCheckRequest(msg, target, text, false))
+			return
+		}
+	}
+
+	if text := strings.TrimSpace(msg.CommandArguments()); text != "" {
+		h.runCheck(ctx, groupCheckRequest(msg, msg, text, false))
+		return
+	}
+
+	h.sendReply(msg.Chat.ID, msg.MessageID, "Ответьте командой /check на сообщение, которое нужно проверить.")
+}
+
+// handleAutoCheckCommand показывает или меняет автопроверку чата.
+// Формат: /autocheck [on|off]; менять могут только администраторы чата.
+func (h *Handler) handleAutoCheckCommand(ctx context.Context, msg *tgbotapi.Message, anonymousAdmin bool) {
+	chatID := msg.Chat.ID
+	settings := h.chatSettings(ctx, chatID)
+
+	var enable bool
+	switch strings.ToLower(strings.TrimSpace(msg.CommandArguments())) {
+	case "":
+		h.sendReply(chatID, msg.MessageID, "Автопроверка сообщений: "+onOff(settings.AutoCheck)+
+			"\nИзменить: <code>/autocheck on</code> или <code>/autocheck off</code> (только администраторы).")
+		return
+	case "on":
+		enable = true
+	case "off":
+		enable = false
+	default:
+		h.sendReply(chatID, msg.MessageID, "Использование: <code>/autocheck on</code> или <code>/autocheck off</code>")
+		return
+	}
+
+	if !anonymousAdmin && !h.isChatAdmin(chatID, msg.From.ID) {
+		h.sendReply(chatID, msg.MessageID, "⛔ Настройки чата могут менять только администраторы.")
+		return
+	}
+
+	settings.AutoCheck = enable
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := h.storage.SaveChatSettings(dbCtx, settings); err != nil {
+		h.logger.Error("failed to save chat settings", "error", err, "chat_id", chatID)
+		h.sendReply(chatID, msg.MessageID, "❌ Не удалось сохранить настройки. Попробуйте позже.")
+		return
+	}
+
+	h.logger.Info("chat settings updated", "chat_id", chatID, "auto_check", enable)
+	h.sendReply(chatID, msg.MessageID, "Автопроверка сообщений: "+onOff(enable))
+}
+
+// groupCheckRequest собирает проверку текста сообщения target по запросу из msg.
+// Лимиты и настройки берутся у того, кто запросил проверку.
+func groupCheckRequest(msg, target *tgbotapi.Message, text string, quiet bool) checkRequest {
+	req := checkRequest{
+		chatID:     msg.Chat.ID,
+		telegramID: msg.From.ID,
+		text:       text,
+		username:   msg.From.UserName,
+		replyTo:    target.MessageID,
+		quiet:      quiet,
+	}
+	req.describe(target)
+	if msg.SenderChat != nil {
+		req.telegramID = msg.SenderChat.ID
+	}
+	return req
+}
+
+// chatSettings возвращает настройки чата или настройки по умолчанию
+func (h *Handler) chatSettings(ctx context.Context, chatID int64) *entity.ChatSettings {
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	settings, err := h.storage.GetChatSettings(dbCtx, chatID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			h.logger.Error("failed to load chat settings", "error", err, "chat_id", chatID)
+		}
+		return entity.DefaultChatSettings(chatID)
+	}
+
+	return settings
+}
+
+// isChatAdmin проверяет, является ли пользователь администратором чата
+func (h *Handler) isChatAdmin(chatID, userID int64) bool {
+	member, err := h.bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID},
+	})
+	if err != nil {
+		h.logger.Error("failed to get chat member", "error", err, "chat_id", chatID, "user_id", userID)
+		return false
+	}
+	return member.IsCreator() || member.IsAdministrator()
+}
+
+// addressedToBot отсекает команды вида /cmd@other_bot
+func (h *Handler) addressedToBot(msg *tgbotapi.Message) bool {
+	_, username, ok := strings.Cut(msg.CommandWithAt(), "@")
+	return !ok || strings.EqualFold(username, h.bot.Self.UserName)
+}
+
+// mentionPattern находит упоминание бота с именем username
+func mentionPattern(username string) *regexp.Regexp {
+	if username == "" {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)@` + regexp.QuoteMeta(username) + `\b`)
+}
+
+// stripMention убирает упоминание бота из текста; false, если бота не упомянули
+func (h *Handler) stripMention(text string) (string, bool) {
+	if h.mention == nil || !h.mention.MatchString(text) {
+		return text, false
+	}
+	return strings.TrimSpace(h.mention.ReplaceAllString(text, "")), true
+}
@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"spell_bot/internal/checker"
+	"spell_bot/internal/entity"
+	"spell_bot/internal/ratelimit"
+	"spell_bot/internal/storage/memory"
+)
+
+// cleanChecker не находит ошибок ни в одном тексте
+type cleanChecker struct{}
+
+func (cleanChecker) Check(context.Context, string, checker.Options) (*checker.CheckResponse, error) {
+	return &checker.CheckResponse{}, nil
+}
+
+func TestQuietCheckWithoutChangesIsNotSaved(t *testing.T) {
+	ctx := context.Background()
+	api, stub := newTestAPI(t)
+	store := memory.NewStorage()
+	h := NewHandler(api, cleanChecker{}, store, testLogger, Options{})
+
+	req := checkRequest{chatID: -100, telegramID: 7, text: "текст", replyTo: 1, settings: entity.DefaultUserSettings(7, false)}
+
+	quiet := req
+	quiet.quiet = true
+	h.processTextCheck(ctx, quiet)
+	if count, err := store.CountChecks(ctx, 7); err != nil || count != 0 {
+		t.Fatalf("history after quiet check = %d, %v; want empty", count, err)
+	}
+	if _, ok := stub.find("sendMessage"); ok {
+		t.Fatalf("quiet check without changes replied, calls: %v", stub.methods())
+	}
+
+	h.processTextCheck(ctx, req)
+	if count, err := store.CountChecks(ctx, 7); err != nil || count != 1 {
+		t.Fatalf("history after explicit check = %d, %v; want 1", count, err)
+	}
+}
+
+func TestQuietCheckUsesChatLimits(t *testing.T) {
+	ctx := context.Background()
+	api, _ := newTestAPI(t)
+	store := memory.NewStorage()
+	limiter := ratelimit.NewLimiter(store, ratelimit.Limits{DailyRequests: 1}, nil)
+	h := NewHandler(api, cleanChecker{}, store, testLogger, Options{Limiter: limiter})
+
+	req := checkRequest{chatID: -100, telegramID: 7, text: "текст", replyTo: 1}
+	quiet := req
+	quiet.quiet = true
+
+	if !h.allowCheck(ctx, quiet) {
+		t.Fatal("first auto-check rejected")
+	}
+	if h.allowCheck(ctx, quiet) {
+		t.Fatal("auto-check over the chat quota allowed")
+	}
+	// Автопроверки не расходуют личную квоту автора сообщений
+	if !h.allowCheck(ctx, req) {
+		t.Fatal("explicit check rejected after auto-checks of the author's messages")
+	}
+}
@@ -2,61 +2,197 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"html"
 	"log/slog"
+	"regexp"
 	"strings"
 	"time"
 
-	"spell_bot/internal/deepseek"
+	"spell_bot/internal/checker"
+	"spell_bot/internal/checker/cache"
 	"spell_bot/internal/entity"
+	"spell_bot/internal/pkg/diff"
+	"spell_bot/internal/pkg/tgformat"
+	"spell_bot/internal/pkg/tgsplit"
 	"spell_bot/internal/storage"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// checkTimeout ограничивает проверку текста вместе со всеми повторными попытками
+const checkTimeout = 2 * time.Minute
+
 type Handler struct {
-	bot      *tgbotapi.BotAPI
-	deepseek *deepseek.Client
-	storage  storage.Storage
-	logger   *slog.Logger
+	bot     *tgbotapi.BotAPI
+	checker checker.Checker
+	storage storage.Storage
+	logger  *slog.Logger
+	opts    Options
+	pending *pendingQueue
+
+	// inlineChecker кеширует ответы на inline-запросы
+	inlineChecker checker.Checker
+
+	// mention - упоминание бота в группах
+	mention *regexp.Regexp
+	// captions - исправленные подписи для копий медиа
+	captions *captionStore
 }
 
-func NewHandler(bot *tgbotapi.BotAPI, deepseek *deepseek.Client, storage storage.Storage, logger *slog.Logger) *Handler {
+func NewHandler(bot *tgbotapi.BotAPI, checker checker.Checker, storage storage.Storage, logger *slog.Logger, opts Options) *Handler {
 	return &Handler{
-		bot:      bot,
-		deepseek: deepseek,
-		storage:  storage,
-		logger:   logger,
+		bot:     bot,
+		checker: checker,
+		storage: storage,
+		logger:  logger,
+		opts:    opts,
+		pending: newPendingQueue(pendingQueueLimit),
+
+		inlineChecker: cache.New(checker, "inline", inlineCacheTTL, opts.Metrics, cache.NewLRU(inlineCacheSize)),
+
+		mention:  mentionPattern(bot.Self.UserName),
+		captions: newCaptionStore(captionStoreLimit, captionTTL),
 	}
 }
 
 func (h *Handler) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	if update.CallbackQuery != nil {
+		h.handleCallback(ctx, update.CallbackQuery)
+		return
+	}
+
+	if update.InlineQuery != nil {
+		h.handleInlineQuery(ctx, update.InlineQuery)
+		return
+	}
+
 	if update.Message == nil {
 		return
 	}
 
+	if isGroupChat(update.Message.Chat) {
+		h.handleGroupMessage(ctx, update.Message)
+		return
+	}
+
 	chatID := update.Message.Chat.ID
-	text := update.Message.Text
 
+	if isDocumentCheck(update.Message) {
+		req := privateCheckRequest(update.Message, "")
+		req.replyTo = update.Message.MessageID
+		h.handleDocument(ctx, update.Message.Document, req)
+		return
+	}
+
+	text, _ := messageText(update.Message)
 	if text == "" {
+		if hasMedia(update.Message) {
+			h.sendMessage(chatID, "🖼 У этого сообщения нет подписи. Я проверяю текст, подписи к фото и видео и документы.")
+			return
+		}
 		h.sendMessage(chatID, "Пожалуйста, отправьте текст для проверки орфографии и пунктуации.")
 		return
 	}
 
-	if strings.HasPrefix(text, "/start") {
-		h.saveUser(ctx, update.Message)
-		h.sendWelcomeMessage(chatID)
+	if h.handleCommand(ctx, update.Message) {
+		return
+	}
+
+	h.runCheck(ctx, privateCheckRequest(update.Message, text))
+}
+
+// privateCheckRequest собирает проверку текста сообщения в личном чате
+func privateCheckRequest(msg *tgbotapi.Message, text string) checkRequest {
+	req := checkRequest{
+		chatID:     msg.Chat.ID,
+		telegramID: msg.Chat.ID,
+		text:       text,
+		username:   msg.Chat.UserName,
+	}
+	req.describe(msg)
+	if msg.From != nil {
+		req.telegramID = msg.From.ID
+	}
+	return req
+}
+
+// describe дополняет проверку сведениями о сообщении target, из которого взят текст:
+// оформлением, медиа с подписью и источником пересылки
+func (req *checkRequest) describe(target *tgbotapi.Message) {
+	text, entities := messageText(target)
+	// Смещения сущностей верны только для текста сообщения целиком
+	if req.text != text {
 		return
 	}
+	req.formatting = tgformat.Parse(text, entities)
+	if target.Text == "" && hasMedia(target) {
+		req.media = target.MessageID
+		req.replyTo = target.MessageID
+	}
+	req.origin = forwardOrigin(target)
+}
 
-	if strings.HasPrefix(text, "/help") {
-		h.saveUser(ctx, update.Message)
+// handleCommand выполняет общие команды; false, если текст не является известной командой
+func (h *Handler) handleCommand(ctx context.Context, msg *tgbotapi.Message) bool {
+	chatID, text := msg.Chat.ID, msg.Text
+
+	switch {
+	case strings.HasPrefix(text, "/start"):
+		h.saveUser(ctx, msg)
+		h.sendWelcomeMessage(chatID)
+
+	case strings.HasPrefix(text, "/help"):
+		h.saveUser(ctx, msg)
 		h.sendHelpMessage(chatID)
+
+	case strings.HasPrefix(text, "/settings"):
+		h.saveUser(ctx, msg)
+		if msg.From != nil {
+			h.sendSettings(ctx, chatID, msg.From.ID)
+		}
+
+	case strings.HasPrefix(text, "/limits"):
+		if msg.From != nil {
+			h.handleLimitsCommand(chatID, msg.From.ID, text)
+		}
+
+	case strings.HasPrefix(text, "/history"):
+		h.saveUser(ctx, msg)
+		if msg.From != nil {
+			h.sendHistory(ctx, chatID, msg.From.ID)
+		}
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// runCheck сверяется с лимитами, подгружает настройки автора и проверяет текст
+func (h *Handler) runCheck(ctx context.Context, req checkRequest) {
+	if !h.allowCheck(ctx, req) {
 		return
 	}
+	req.settings = h.userSettings(ctx, req.telegramID)
+
+	h.processTextCheck(ctx, req)
+}
 
-	h.processTextCheck(ctx, chatID, text, update.Message.Chat.UserName)
+// handleCallback маршрутизирует нажатия на inline-кнопки
+func (h *Handler) handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	switch {
+	case strings.HasPrefix(query.Data, "history:"):
+		h.handleHistoryCallback(ctx, query)
+	case strings.HasPrefix(query.Data, callbackSettings):
+		h.handleSettingsCallback(ctx, query)
+	case strings.HasPrefix(query.Data, callbackCaption):
+		h.handleCaptionCallback(query)
+	default:
+		h.answerCallback(query.ID, "")
+	}
 }
 
 // saveUser сохраняет или обновляет информацию о пользователе
@@ -64,6 +200,9 @@ func (h *Handler) saveUser(ctx context.Context, msg *tgbotapi.Message) error {
 	if msg.From == nil {
 		return nil // Сообщения от каналов не имеют From
 	}
+	if !msg.Chat.IsPrivate() {
+		return nil // users хранит личный чат пользователя
+	}
 
 	user := entity.NewUser(
 		msg.From.ID,
@@ -90,46 +229,244 @@ func (h *Handler) saveUser(ctx context.Context, msg *tgbotapi.Message) error {
 	return nil
 }
 
-func (h *Handler) processTextCheck(ctx context.Context, chatID int64, text string, user string) {
-	h.logger.Info("processing text check", "chat_id", chatID, "text_length", len(text), "username", user)
+func (h *Handler) processTextCheck(ctx context.Context, req checkRequest) {
+	chatID, text := req.chatID, req.text
+	h.logger.Info("processing text check", "chat_id", chatID, "text_length", len(text), "username", req.username)
 
 	// Send "typing" action
-	h.sendChatAction(chatID, tgbotapi.ChatTyping)
+	if !req.quiet {
+		h.sendChatAction(chatID, tgbotapi.ChatTyping)
+	}
 
-	// Check text with DeepSeek
-	response, err := h.deepseek.CheckSpellingAndPunctuation(ctx, text)
+	response, err := h.check(ctx, &req)
+	if err != nil && req.quiet {
+		h.logger.Warn("background check failed", "error", err, "chat_id", chatID)
+		return
+	}
+	if errors.Is(err, checker.ErrUnavailable) {
+		h.logger.Warn("checker unavailable, text queued", "chat_id", chatID)
+		if h.pending.push(req) {
+			h.sendReply(chatID, req.replyTo, "⏳ Проверка временно недоступна. Я сохранил текст и пришлю результат, как только сервис восстановится.")
+		} else {
+			h.sendReply(chatID, req.replyTo, "⏳ Проверка временно недоступна. Пожалуйста, попробуйте позже.")
+		}
+		return
+	}
 	if err != nil {
 		h.logger.Error("failed to check text", "error", err, "chat_id", chatID)
-		h.sendMessage(chatID, "❌ Произошла ошибка при проверке текста. Пожалуйста, попробуйте позже.")
+		h.sendReply(chatID, req.replyTo, "❌ Произошла ошибка при проверке текста. Пожалуйста, попробуйте позже.")
 		return
 	}
 
-	h.sendCorrectionResults(chatID, text, response)
+	h.sendCorrectionResults(req, response)
+}
+
+// check проверяет текст настроенным checker с общим таймаутом и записывает результат в историю
+// (кроме фоновых проверок, не нашедших ошибок).
+// Текст с оформлением проверяется с заполнителями вместо кода и ссылок, а результат
+// выводится в req.formatted; если модель испортила заполнители, текст проверяется
+// повторно без оформления.
+func (h *Handler) check(ctx context.Context, req *checkRequest) (*checker.CheckResponse, error) {
+	text := req.text
+	if req.formatting != nil {
+		text = req.formatting.Masked()
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, h.checkTimeoutFor(text))
+	defer cancel()
+
+	start := time.Now()
+	response, err := h.checker.Check(checkCtx, text, checker.Options{
+		Strictness: req.settings.Strictness,
+		Language:   req.settings.Language,
+	})
+
+	if err == nil && req.formatting != nil {
+		if response.HasChanges && !req.formatting.Restorable(response.CorrectedText) {
+			h.logger.Warn("checker broke formatting placeholders, retrying without formatting", "chat_id", req.chatID)
+			req.formatting = nil
+			return h.check(ctx, req)
+		}
+		response = h.restoreFormatting(req, response)
+	}
+
+	if req.deferred && errors.Is(err, checker.ErrUnavailable) {
+		// Каждый тик очереди иначе дублировал бы запись о недоступности
+		return response, err
+	}
+
+	record := entity.NewCheck(req.telegramID, req.chatID, req.text)
+	record.Latency = time.Since(start)
+	switch {
+	case errors.Is(err, checker.ErrUnavailable):
+		record.Status = entity.CheckStatusUnavailable
+		record.Error = err.Error()
+	case err != nil:
+		record.Status = entity.CheckStatusFailed
+		record.Error = err.Error()
+	default:
+		record.Status = entity.CheckStatusOK
+		record.CorrectedText = response.CorrectedText
+		if !response.HasChanges {
+			record.CorrectedText = req.text
+		}
+		record.Explanation = response.Explanation
+		record.Model = response.Model
+		record.PromptTokens = response.Usage.PromptTokens
+		record.CompletionTokens = response.Usage.CompletionTokens
+		record.TotalTokens = response.Usage.TotalTokens
+	}
+
+	h.opts.Metrics.CheckDone(record.Status, record.Latency)
+
+	// Фоновая проверка без исправлений не попадает в историю участника
+	if req.quiet && err == nil && !response.HasChanges {
+		return response, err
+	}
+
+	if saveErr := h.saveCheck(ctx, record); saveErr != nil {
+		h.logger.Error("failed to save check", "error", saveErr, "chat_id", req.chatID)
+	}
+
+	return response, err
+}
+
+// checkTimeoutFor возвращает таймаут проверки text: длинный текст проверяется
+// фрагментами в несколько волн, и каждой волне отводится checkTimeout
+func (h *Handler) checkTimeoutFor(text string) time.Duration {
+	if chunked, ok := h.checker.(interface{ Rounds(string) int }); ok {
+		return checkTimeout * time.Duration(chunked.Rounds(text))
+	}
+	return checkTimeout
+}
+
+// restoreFormatting выводит результат проверки текста с оформлением в req.formatted
+// и возвращает копию ответа, в которой заполнители заменены исходными фрагментами
+func (h *Handler) restoreFormatting(req *checkRequest, response *checker.CheckResponse) *checker.CheckResponse {
+	masked := response.CorrectedText
+	if !response.HasChanges {
+		masked = req.formatting.Masked()
+	}
+	req.formatted = req.formatting.HTML(masked)
+
+	// Ответ может лежать в кеше, поэтому меняется только копия
+	restored := *response
+	restored.CorrectedText = req.formatting.Unmask(response.CorrectedText)
+	restored.Explanation = req.formatting.Unmask(response.Explanation)
+	restored.Edits = make([]checker.Edit, 0, len(response.Edits))
+	for _, e := range response.Edits {
+		// Смещения правок относятся к тексту с заполнителями; правка,
+		// задевающая часть заполнителя, не имеет места в исходном тексте
+		start, okStart := req.formatting.UnmaskOffset(e.Start)
+		end, okEnd := req.formatting.UnmaskOffset(e.End)
+		if !okStart || !okEnd {
+			continue
+		}
+		e.Start, e.End = start, end
+		e.Original = req.formatting.Unmask(e.Original)
+		e.Replacement = req.formatting.Unmask(e.Replacement)
+		restored.Edits = append(restored.Edits, e)
+	}
+	return &restored
+}
+
+// saveCheck сохраняет запись о проверке в истории
+func (h *Handler) saveCheck(ctx context.Context, check *entity.Check) error {
+	// Используем контекст с таймаутом для операции с БД
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := h.storage.SaveCheck(dbCtx, check); err != nil {
+		return fmt.Errorf("failed to save check: %w", err)
+	}
+
+	return nil
 }
 
-func (h *Handler) sendCorrectionResults(chatID int64, originalText string, response *deepseek.CheckResponse) {
+// sendCorrectionResults отвечает результатом проверки; фоновые проверки
+// сообщают только о найденных ошибках
+func (h *Handler) sendCorrectionResults(req checkRequest, response *checker.CheckResponse) {
+	if req.quiet && !response.HasChanges {
+		return
+	}
+
+	originalText, settings := req.text, req.settings
 	var result strings.Builder
+	result.WriteString(sourceHeader(req))
 
 	if !response.HasChanges {
 		result.WriteString("✅ <b>Текст проверен и не требует исправлений!</b>\n\n")
 		result.WriteString("📝 <b>Исходный текст:</b>\n")
-		result.WriteString("<code>")
-		result.WriteString(h.escapeHTML(originalText))
-		result.WriteString("</code>")
+		result.WriteString(h.formatResult(req, originalText))
 	} else {
 		result.WriteString("✏️ <b>Текст исправлен!</b>\n\n")
 		result.WriteString("📝 <b>Исправленный текст:</b>\n")
-		result.WriteString("<code>")
-		result.WriteString(h.escapeHTML(response.CorrectedText))
-		result.WriteString("</code>")
+		result.WriteString(h.formatResult(req, response.CorrectedText))
+
+		if settings.DiffView {
+			if segments := diff.Words(originalText, response.CorrectedText); diff.HasChanges(segments) {
+				result.WriteString("\n\n🔍 <b>Изменения:</b>\n")
+				result.WriteString(diff.RenderHTML(segments))
+			}
+		}
 
-		if response.Explanation != "" {
-			result.WriteString("\n\n💡 <b>Исправления:</b>\n")
-			result.WriteString(h.escapeHTML(response.Explanation))
+		if settings.ShowExplanations {
+			if len(response.Edits) > 0 {
+				result.WriteString(fmt.Sprintf("\n\n💡 <b>Исправления (%d):</b>\n", len(response.Edits)))
+				result.WriteString(h.formatEdits(response.Edits))
+			} else if response.Explanation != "" {
+				result.WriteString("\n\n💡 <b>Исправления:</b>\n")
+				result.WriteString(h.escapeHTML(response.Explanation))
+			}
 		}
 	}
 
-	h.sendMessage(chatID, result.String())
+	var markup *tgbotapi.InlineKeyboardMarkup
+	if response.HasChanges && req.media != 0 {
+		markup = h.captionKeyboard(req, response.CorrectedText)
+	}
+
+	h.sendReplyMarkup(req.chatID, req.replyTo, result.String(), markup)
+}
+
+// formatText выводит текст в формате, выбранном пользователем
+func (h *Handler) formatText(text string, settings *entity.UserSettings) string {
+	if settings.OutputFormat == entity.OutputFormatPlain {
+		return h.escapeHTML(text)
+	}
+	return "<code>" + h.escapeHTML(text) + "</code>"
+}
+
+// formatResult выводит текст результата; текст с оформлением выводится
+// с ним, а не в блоке кода, где Telegram оформление не показывает
+func (h *Handler) formatResult(req checkRequest, text string) string {
+	if req.formatted != "" {
+		return req.formatted
+	}
+	return h.formatText(text, req.settings)
+}
+
+// categoryNames - подписи категорий исправлений для пользователя
+var categoryNames = map[string]string{
+	checker.CategorySpelling:    "орфография",
+	checker.CategoryPunctuation: "пунктуация",
+	checker.CategoryGrammar:     "грамматика",
+	checker.CategoryStyle:       "стиль",
+	checker.CategoryOther:       "другое",
+}
+
+// formatEdits выводит список исправлений по одному на строку
+func (h *Handler) formatEdits(edits []checker.Edit) string {
+	lines := make([]string, 0, len(edits))
+	for _, e := range edits {
+		line := fmt.Sprintf("• <s>%s</s> → <b>%s</b> <i>(%s)</i>",
+			h.escapeHTML(e.Original), h.escapeHTML(e.Replacement), categoryNames[e.Category])
+		if e.Rationale != "" {
+			line += " — " + h.escapeHTML(e.Rationale)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
 }
 
 func (h *Handler) sendWelcomeMessage(chatID int64) {
@@ -138,7 +475,7 @@ func (h *Handler) sendWelcomeMessage(chatID int64) {
 Я помогу вам исправить орфографические, пунктуационные и грамматические ошибки в ваших текстах.
 
 <b>Как использовать:</b>
-1. Отправьте мне текст на русском языке
+1. Отправьте мне текст на русском языке или документ (.txt, .md, .docx, .odt)
 2. Я исправлю все ошибки
 3. Верну вам исправленный текст в удобном для копирования формате
 
@@ -150,6 +487,8 @@ func (h *Handler) sendWelcomeMessage(chatID int64) {
 <b>Команды:</b>
 /start - показать это сообщение
 /help - получить справку
+/history - история проверок
+/settings - настройки
 
 Отправьте текст для исправления! ✏️`
 
@@ -174,22 +513,73 @@ func (h *Handler) sendHelpMessage(chatID int64) {
 
 <b>Особенности:</b>
 • Сохраняю смысл, тон и стиль вашего текста
-• Работаю только с русским языком
+• Сохраняю оформление сообщения (жирный, курсив, ссылки), а код, ссылки и упоминания не трогаю
+• Проверяю тексты на русском, английском и украинском (язык выбирается в /settings)
 • Обрабатываю тексты любой длины
+• Проверяю подписи к фото и видео и пересланные сообщения; исправленную подпись можно получить вместе с медиа
+• Проверяю документы .txt, .md, .docx и .odt и возвращаю исправленный файл
+• Работаю в любом чате: наберите @%s и текст, чтобы отправить его уже исправленным
+• В группах проверяю сообщения по упоминанию или команде /check в ответ на сообщение
 
 <b>Пример:</b>
 Просто отправьте: "Превет мир как у тибя дила?"
 Я отвечу: "Привет, мир! Как у тебя дела?"`
 
-	h.sendMessage(chatID, message)
+	h.sendMessage(chatID, fmt.Sprintf(message, h.bot.Self.UserName))
 }
 
+// sendMessage отправляет HTML-сообщение, при необходимости разбивая его на части
 func (h *Handler) sendMessage(chatID int64, text string) {
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "HTML"
+	h.sendReply(chatID, 0, text)
+}
+
+// sendReply отправляет HTML-сообщение ответом на сообщение replyTo (0 - без ответа).
+// Ответом оформляется только первая часть длинного сообщения.
+func (h *Handler) sendReply(chatID int64, replyTo int, text string) {
+	h.sendReplyMarkup(chatID, replyTo, text, nil)
+}
+
+// sendReplyMarkup отправляет ответ как sendReply; клавиатура markup
+// прикрепляется к последней части сообщения
+func (h *Handler) sendReplyMarkup(chatID int64, replyTo int, text string, markup *tgbotapi.InlineKeyboardMarkup) {
+	parts := tgsplit.SplitHTML(text, tgsplit.MessageLimit)
+	for i, part := range parts {
+		msg := tgbotapi.NewMessage(chatID, part)
+		msg.ParseMode = "HTML"
+		if i == 0 && replyTo != 0 {
+			msg.ReplyToMessageID = replyTo
+			msg.AllowSendingWithoutReply = true
+		}
+		if i == len(parts)-1 && markup != nil {
+			msg.ReplyMarkup = markup
+		}
+
+		if _, err := h.bot.Send(msg); err != nil {
+			h.logger.Error("failed to send message", "error", err, "chat_id", chatID, "text", part)
+			return
+		}
+	}
+}
+
+// answerCallback подтверждает получение callback query, при необходимости показывая уведомление
+func (h *Handler) answerCallback(queryID, text string) {
+	if _, err := h.bot.Request(tgbotapi.NewCallback(queryID, text)); err != nil {
+		h.logger.Error("failed to answer callback query", "error", err)
+	}
+}
+
+// editCallbackMessage заменяет текст и клавиатуру сообщения, к которому привязана кнопка
+func (h *Handler) editCallbackMessage(query *tgbotapi.CallbackQuery, text string, markup *tgbotapi.InlineKeyboardMarkup) {
+	if query.Message == nil {
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = markup
 
-	if _, err := h.bot.Send(msg); err != nil {
-		h.logger.Error("failed to send message", "error", err, "chat_id", chatID, "text", text)
+	if _, err := h.bot.Send(edit); err != nil {
+		h.logger.Error("failed to edit message", "error", err, "chat_id", query.Message.Chat.ID)
 	}
 }
 
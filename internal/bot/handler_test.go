@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+
+	"spell_bot/internal/checker"
+	"spell_bot/internal/pkg/tgformat"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestRestoreFormattingRemapsEdits(t *testing.T) {
+	text := "Вызови fmt.Println и провирь вывод"
+	req := &checkRequest{
+		text:       text,
+		formatting: tgformat.Parse(text, []tgbotapi.MessageEntity{{Type: "code", Offset: 7, Length: 11}}),
+	}
+	response := &checker.CheckResponse{
+		HasChanges:    true,
+		CorrectedText: "Вызови ⟦0⟧ и проверь вывод",
+		Edits: []checker.Edit{
+			{Start: 13, End: 20, Original: "провирь", Replacement: "проверь"},
+			// Правка внутри заполнителя не соответствует исходному тексту
+			{Start: 8, End: 9, Original: "0", Replacement: "1"},
+		},
+	}
+
+	restored := (&Handler{}).restoreFormatting(req, response)
+
+	want := []checker.Edit{{Start: 21, End: 28, Original: "провирь", Replacement: "проверь"}}
+	if !reflect.DeepEqual(restored.Edits, want) {
+		t.Errorf("Edits = %+v, want %+v", restored.Edits, want)
+	}
+	if got := string([]rune(text)[21:28]); got != "провирь" {
+		t.Errorf("edit points at %q", got)
+	}
+	if restored.CorrectedText != "Вызови fmt.Println и проверь вывод" {
+		t.Errorf("CorrectedText = %q", restored.CorrectedText)
+	}
+	if req.formatted != "Вызови <code>fmt.Println</code> и проверь вывод" {
+		t.Errorf("formatted = %q", req.formatted)
+	}
+	// Закешированный ответ не меняется
+	if response.Edits[0].Start != 13 {
+		t.Error("original response was modified")
+	}
+}
+
+func TestIsDocumentCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  tgbotapi.Message
+		want bool
+	}{
+		{"text", tgbotapi.Message{Text: "текст"}, false},
+		{"supported", tgbotapi.Message{Document: &tgbotapi.Document{FileName: "a.docx"}, Caption: "подпись"}, true},
+		{"unsupported without caption", tgbotapi.Message{Document: &tgbotapi.Document{FileName: "a.pdf"}}, true},
+		{"unsupported with caption", tgbotapi.Message{Document: &tgbotapi.Document{FileName: "a.pdf"}, Caption: "подпись"}, false},
+	}
+	for _, tt := range tests {
+		if got := isDocumentCheck(&tt.msg); got != tt.want {
+			t.Errorf("%s: isDocumentCheck = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"spell_bot/internal/entity"
+	"spell_bot/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	historyPageSize = 5
+	// historyTextLimit ограничивает длину текстов в карточке записи,
+	// чтобы отредактированное сообщение уложилось в лимит Telegram
+	historyTextLimit = 1500
+	historyTitleLen  = 30
+
+	callbackHistoryPage = "history:page:"
+	callbackHistoryOpen = "history:open:"
+)
+
+// sendHistory отправляет первую страницу истории проверок новым сообщением
+func (h *Handler) sendHistory(ctx context.Context, chatID, telegramID int64) {
+	text, markup, err := h.renderHistoryPage(ctx, telegramID, 0)
+	if err != nil {
+		h.logger.Error("failed to load history", "error", err, "chat_id", chatID)
+		h.sendMessage(chatID, "❌ Не удалось загрузить историю. Пожалуйста, попробуйте позже.")
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if markup != nil {
+		msg.ReplyMarkup = *markup
+	}
+	if _, err := h.bot.Send(msg); err != nil {
+		h.logger.Error("failed to send history", "error", err, "chat_id", chatID)
+	}
+}
+
+// handleHistoryCallback обрабатывает переключение страниц и открытие записи истории
+func (h *Handler) handleHistoryCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	var (
+		text   string
+		markup *tgbotapi.InlineKeyboardMarkup
+		err    error
+	)
+
+	switch {
+	case strings.HasPrefix(query.Data, callbackHistoryPage):
+		page, _ := strconv.Atoi(strings.TrimPrefix(query.Data, callbackHistoryPage))
+		text, markup, err = h.renderHistoryPage(ctx, query.From.ID, page)
+	case strings.HasPrefix(query.Data, callbackHistoryOpen):
+		text, markup, err = h.renderHistoryEntry(ctx, query.From.ID, strings.TrimPrefix(query.Data, callbackHistoryOpen))
+	default:
+		return
+	}
+
+	if errors.Is(err, storage.ErrNotFound) {
+		h.answerCallback(query.ID, "Запись не найдена")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to render history", "error", err, "data", query.Data)
+		h.answerCallback(query.ID, "Не удалось загрузить историю")
+		return
+	}
+
+	h.answerCallback(query.ID, "")
+	h.editCallbackMessage(query, text, markup)
+}
+
+// renderHistoryPage формирует страницу списка проверок пользователя
+func (h *Handler) renderHistoryPage(ctx context.Context, telegramID int64, page int) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	total, err := h.storage.CountChecks(dbCtx, telegramID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to count checks: %w", err)
+	}
+	if total == 0 {
+		return "📚 <b>История пуста.</b>\n\nОтправьте текст, и он появится здесь после проверки.", nil, nil
+	}
+
+	pages := (total + historyPageSize - 1) / historyPageSize
+	page = min(max(page, 0), pages-1)
+
+	checks, err := h.storage.ListChecks(dbCtx, telegramID, historyPageSize, page*historyPageSize)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to list checks: %w", err)
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, c := range checks {
+		label := fmt.Sprintf("%s %s %s", statusIcon(c.Status), c.CreatedAt.Local().Format("02.01 15:04"), truncate(oneLine(c.OriginalText), historyTitleLen))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("%s%d:%d", callbackHistoryOpen, c.ID, page)),
+		))
+	}
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("◀️ Новее", callbackHistoryPage+strconv.Itoa(page-1)))
+	}
+	if page < pages-1 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("Старее ▶️", callbackHistoryPage+strconv.Itoa(page+1)))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	text := fmt.Sprintf("📚 <b>История проверок</b> (стр. %d/%d, всего %d)\n\nВыберите запись, чтобы открыть её.", page+1, pages, total)
+
+	return text, &markup, nil
+}
+
+// renderHistoryEntry формирует карточку одной проверки; data имеет вид "<id>:<страница списка>"
+func (h *Handler) renderHistoryEntry(ctx context.Context, telegramID int64, data string) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	rawID, page, _ := strings.Cut(data, ":")
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return "", nil, storage.ErrNotFound
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	check, err := h.storage.GetCheck(dbCtx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	// Пользователь видит только свои проверки
+	if check.TelegramID != telegramID {
+		return "", nil, storage.ErrNotFound
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s <b>Проверка от %s</b>\n\n", statusIcon(check.Status), check.CreatedAt.Local().Format("02.01.2006 15:04")))
+	b.WriteString("📝 <b>Исходный текст:</b>\n<code>")
+	b.WriteString(h.escapeHTML(truncate(check.OriginalText, historyTextLimit)))
+	b.WriteString("</code>")
+
+	switch check.Status {
+	case entity.CheckStatusOK:
+		b.WriteString("\n\n✏️ <b>Исправленный текст:</b>\n<code>")
+		b.WriteString(h.escapeHTML(truncate(check.CorrectedText, historyTextLimit)))
+		b.WriteString("</code>")
+	case entity.CheckStatusUnavailable:
+		b.WriteString("\n\n⏳ Проверка была отложена: сервис был недоступен.")
+	default:
+		b.WriteString("\n\n❌ Проверка завершилась ошибкой.")
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("« К списку", callbackHistoryPage+page),
+	))
+
+	return b.String(), &markup, nil
+}
+
+func statusIcon(status string) string {
+	switch status {
+	case entity.CheckStatusOK:
+		return "✅"
+	case entity.CheckStatusUnavailable:
+		return "⏳"
+	default:
+		return "❌"
+	}
+}
+
+// truncate обрезает строку до limit символов, добавляя многоточие
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
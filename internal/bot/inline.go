@@ -0,0 +1,160 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"spell_bot/internal/checker"
+	"spell_bot/internal/entity"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// inlineDebounce - пауза, после которой запрос считается набранным;
+	// Telegram присылает новый inline-запрос почти на каждое нажатие клавиши
+	inlineDebounce = 700 * time.Millisecond
+	// inlineCheckTimeout короче checkTimeout: ответ на inline-запрос ждут недолго
+	inlineCheckTimeout = 15 * time.Second
+
+	inlineCacheSize = 500
+	inlineCacheTTL  = 10 * time.Minute
+	// inlineCacheTime - сколько секунд Telegram может сам отдавать наш ответ на тот же запрос
+	inlineCacheTime = 300
+
+	inlineDescriptionLimit = 100
+)
+
+// debouncer откладывает вызов до паузы в запросах пользователя: новый запрос
+// отменяет ещё не выполненный предыдущий, поэтому выполняется только последний
+type debouncer struct {
+	mu      sync.Mutex
+	timers  map[int64]*time.Timer
+	stopped bool
+}
+
+func newDebouncer() *debouncer {
+	return &debouncer{timers: make(map[int64]*time.Timer)}
+}
+
+// debounce вызывает fn через delay, если за это время для userID не пришёл
+// новый вызов. fn выполняется в отдельной горутине.
+func (d *debouncer) debounce(userID int64, delay time.Duration, fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return
+	}
+	if prev, ok := d.timers[userID]; ok {
+		prev.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		d.mu.Lock()
+		latest := d.timers[userID] == timer
+		if latest {
+			delete(d.timers, userID)
+		}
+		d.mu.Unlock()
+
+		if latest {
+			fn()
+		}
+	})
+	d.timers[userID] = timer
+}
+
+// stop отменяет все отложенные вызовы и перестаёт принимать новые
+func (d *debouncer) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopped = true
+	for userID, timer := range d.timers {
+		timer.Stop()
+		delete(d.timers, userID)
+	}
+}
+
+// handleInlineQuery отвечает на `@bot текст` статьёй с исправленным текстом
+func (h *Handler) handleInlineQuery(ctx context.Context, query *tgbotapi.InlineQuery) {
+	text := strings.TrimSpace(query.Query)
+	if text == "" || query.From == nil {
+		return
+	}
+
+	if h.opts.Limiter != nil {
+		dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		decision, err := h.opts.Limiter.Allow(dbCtx, query.From.ID, utf8.RuneCountInString(text))
+		cancel()
+		if err != nil {
+			h.logger.Error("failed to check rate limits", "error", err, "telegram_id", query.From.ID)
+		} else if !decision.Allowed {
+			h.opts.Metrics.CheckDone(checkOutcomeLimited, 0)
+			h.answerInline(query.ID, 0, inlineArticle("limit", "⏳ Лимит проверок исчерпан",
+				"Попробуйте снова через "+formatWait(time.Until(decision.RetryAt)), text))
+			return
+		}
+	}
+
+	settings := h.userSettings(ctx, query.From.ID)
+
+	checkCtx, cancel := context.WithTimeout(ctx, inlineCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	response, err := h.inlineChecker.Check(checkCtx, text, checker.Options{
+		Strictness: settings.Strictness,
+		Language:   settings.Language,
+	})
+
+	switch {
+	case errors.Is(err, checker.ErrUnavailable):
+		h.opts.Metrics.CheckDone(entity.CheckStatusUnavailable, time.Since(start))
+		h.answerInline(query.ID, 0, inlineArticle("unavailable", "⏳ Проверка временно недоступна",
+			"Отправить текст без изменений", text))
+		return
+	case err != nil:
+		h.opts.Metrics.CheckDone(entity.CheckStatusFailed, time.Since(start))
+		h.logger.Error("failed to check inline query", "error", err, "telegram_id", query.From.ID)
+		h.answerInline(query.ID, 0, inlineArticle("failed", "❌ Не удалось проверить текст",
+			"Отправить текст без изменений", text))
+		return
+	}
+	h.opts.Metrics.CheckDone(entity.CheckStatusOK, time.Since(start))
+
+	corrected := response.CorrectedText
+	title := "✅ Отправить исправленный текст"
+	if !response.HasChanges || corrected == "" {
+		corrected = text
+		title = "👍 Ошибок не найдено"
+	}
+
+	h.answerInline(query.ID, inlineCacheTime,
+		inlineArticle("corrected", title, truncate(oneLine(corrected), inlineDescriptionLimit), corrected))
+}
+
+func inlineArticle(id, title, description, text string) tgbotapi.InlineQueryResultArticle {
+	article := tgbotapi.NewInlineQueryResultArticle(id, title, text)
+	article.Description = description
+	return article
+}
+
+func (h *Handler) answerInline(queryID string, cacheTime int, results ...any) {
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: queryID,
+		Results:       results,
+		CacheTime:     cacheTime,
+		// Результат зависит от настроек пользователя
+		IsPersonal: true,
+	}
+	if _, err := h.bot.Request(answer); err != nil {
+		h.logger.Error("failed to answer inline query", "error", err)
+	}
+}
@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestDebouncerRunsOnlyLatestCall(t *testing.T) {
+	d := newDebouncer()
+
+	var (
+		mu    sync.Mutex
+		calls []int
+	)
+	done := make(chan struct{}, 3)
+	for i := 1; i <= 3; i++ {
+		d.debounce(1, 30*time.Millisecond, func() {
+			mu.Lock()
+			calls = append(calls, i)
+			mu.Unlock()
+			done <- struct{}{}
+		})
+	}
+	// Вызовы другого пользователя не отменяют друг друга
+	d.debounce(2, 30*time.Millisecond, func() { done <- struct{}{} })
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("debounced call did not run")
+		}
+	}
+	time.Sleep(60 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != 1 || calls[0] != 3 {
+		t.Errorf("calls = %v, want only the last one", calls)
+	}
+}
+
+func TestDebouncerStopCancelsPendingCalls(t *testing.T) {
+	d := newDebouncer()
+
+	called := make(chan struct{}, 2)
+	d.debounce(1, 20*time.Millisecond, func() { called <- struct{}{} })
+	d.stop()
+	d.debounce(1, 20*time.Millisecond, func() { called <- struct{}{} })
+
+	select {
+	case <-called:
+		t.Error("call ran after stop")
+	case <-time.After(60 * time.Millisecond):
+	}
+}
+
+func TestDispatchDoesNotHoldWorkersForInlineQueries(t *testing.T) {
+	b, _ := newTestBot(t)
+
+	for i := 0; i < 20; i++ {
+		update := inlineUpdate(i)
+		start := time.Now()
+		if err := b.dispatch(t.Context(), update); err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+		if time.Since(start) > 100*time.Millisecond {
+			t.Fatal("dispatch blocked on an inline query")
+		}
+	}
+	if stats := b.pool.Stats(); stats.Queued+stats.InFlight != 0 {
+		t.Errorf("inline queries reached the pool before the debounce pause: %+v", stats)
+	}
+}
+
+// inlineUpdate - очередной inline-запрос одного и того же пользователя
+func inlineUpdate(n int) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: n,
+		InlineQuery: &tgbotapi.InlineQuery{
+			ID:    strconv.Itoa(n),
+			From:  &tgbotapi.User{ID: 42},
+			Query: strings.Repeat("т", n+1),
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"spell_bot/internal/ratelimit"
+)
+
+// checkOutcomeLimited - исход проверки, отклонённой лимитами, для метрик
+const checkOutcomeLimited = "limited"
+
+// allowCheck сверяется с лимитами пользователя и сообщает ему об отказе.
+// Ошибка хранилища только логируется: решение о пропуске принимает Limiter.
+// Автопроверка в группе расходует лимиты чата, а не личные лимиты участника,
+// который её не запрашивал.
+func (h *Handler) allowCheck(ctx context.Context, req checkRequest) bool {
+	if h.opts.Limiter == nil {
+		return true
+	}
+
+	subject := req.telegramID
+	if req.quiet {
+		subject = req.chatID
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	decision, err := h.opts.Limiter.Allow(dbCtx, subject, utf8.RuneCountInString(req.text))
+	if err != nil {
+		h.logger.Error("failed to check rate limits", "error", err, "telegram_id", req.telegramID, "subject", subject)
+	}
+	if decision.Allowed {
+		return true
+	}
+
+	h.logger.Info("check rejected by rate limiter", "telegram_id", req.telegramID, "subject", subject, "reason", decision.Reason)
+	h.opts.Metrics.CheckDone(checkOutcomeLimited, 0)
+	if req.quiet {
+		return false
+	}
+
+	wait := formatWait(time.Until(decision.RetryAt))
+	switch decision.Reason {
+	case ratelimit.ReasonRate:
+		h.sendReply(req.chatID, req.replyTo, fmt.Sprintf("🐢 Слишком много запросов подряд. Попробуйте снова через %s.", wait))
+	case ratelimit.ReasonDailyRequests:
+		h.sendReply(req.chatID, req.replyTo, fmt.Sprintf("📅 Дневной лимит проверок исчерпан. Он обновится через %s (в 00:00 UTC).", wait))
+	case ratelimit.ReasonDailyChars:
+		h.sendReply(req.chatID, req.replyTo, fmt.Sprintf("📅 Дневной лимит по объёму текста исчерпан. Он обновится через %s (в 00:00 UTC). Можно отправить текст покороче.", wait))
+	}
+
+	return false
+}
+
+// handleLimitsCommand показывает или меняет лимиты; доступно только администраторам.
+// Формат: /limits [rate=10] [burst=3] [requests=200] [chars=200000]
+func (h *Handler) handleLimitsCommand(chatID, telegramID int64, text string) {
+	if h.opts.Limiter == nil || !h.opts.Limiter.IsAdmin(telegramID) {
+		h.sendMessage(chatID, "⛔ Команда доступна только администраторам.")
+		return
+	}
+
+	limits := h.opts.Limiter.Limits()
+	args := strings.Fields(text)[1:]
+
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		n, err := strconv.ParseFloat(value, 64)
+		if !ok || err != nil || n < 0 {
+			h.sendMessage(chatID, fmt.Sprintf("❌ Некорректный параметр: <code>%s</code>", h.escapeHTML(arg)))
+			return
+		}
+
+		switch key {
+		case "rate":
+			limits.PerMinute = n
+		case "burst":
+			limits.Burst = int(n)
+		case "requests":
+			limits.DailyRequests = int(n)
+		case "chars":
+			limits.DailyChars = int(n)
+		default:
+			h.sendMessage(chatID, fmt.Sprintf("❌ Неизвестный параметр: <code>%s</code>", h.escapeHTML(key)))
+			return
+		}
+	}
+
+	if len(args) > 0 {
+		h.opts.Limiter.SetLimits(limits)
+		h.logger.Info("rate limits updated", "telegram_id", telegramID, "limits", limits)
+	}
+
+	h.sendMessage(chatID, fmt.Sprintf(`⚖️ <b>Лимиты</b> (0 - без ограничения)
+
+rate = %g запросов в минуту
+burst = %d запросов подряд
+requests = %d проверок в сутки
+chars = %d символов в сутки
+
+Изменить: <code>/limits rate=10 burst=3 requests=200 chars=200000</code>`,
+		limits.PerMinute, limits.Burst, limits.DailyRequests, limits.DailyChars))
+}
+
+// formatWait выводит длительность в виде "2 ч 5 мин", "3 мин" или "15 сек"
+func formatWait(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%d сек", max(int(d.Round(time.Second).Seconds()), 1))
+	case d < time.Hour:
+		return fmt.Sprintf("%d мин", int(d.Round(time.Minute).Minutes()))
+	default:
+		d = d.Round(time.Minute)
+		return fmt.Sprintf("%d ч %d мин", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
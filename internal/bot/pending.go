@@ -0,0 +1,136 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"spell_bot/internal/checker"
+	"spell_bot/internal/entity"
+	"spell_bot/internal/pkg/tgformat"
+)
+
+const (
+	// pendingQueueLimit ограничивает число текстов, ожидающих восстановления бэкенда
+	pendingQueueLimit = 1000
+	// pendingRetryInterval - период повторной проверки отложенных текстов
+	pendingRetryInterval = 15 * time.Second
+)
+
+// checkRequest - текст пользователя, отправленный на проверку
+type checkRequest struct {
+	chatID     int64
+	telegramID int64
+	text       string
+	username   string
+	settings   *entity.UserSettings
+
+	// replyTo - сообщение, на которое отвечает бот (в группах и для документов); 0 - обычное сообщение
+	replyTo int
+	// quiet - фоновая проверка (автопроверка в группе): бот отвечает только
+	// при найденных ошибках и не сообщает о сбоях и лимитах; лимиты считаются
+	// на чат, а проверки без ошибок не сохраняются в историю
+	quiet bool
+
+	// media - сообщение с медиа, подпись которого проверяется; 0 - обычный текст
+	media int
+	// origin - источник пересланного сообщения; пустая строка, если оно не переслано
+	origin string
+
+	// formatting - оформление и защищённые фрагменты текста; nil, если их нет
+	formatting *tgformat.Text
+	// formatted - результат проверки в Telegram HTML с оформлением; заполняется check
+	formatted string
+
+	// deferred - повтор отложенной проверки: недоступность бэкенда для неё
+	// уже записана в историю и метрики при первой попытке
+	deferred bool
+}
+
+// pendingQueue - ограниченная FIFO-очередь проверок, отложенных из-за недоступности бэкенда
+type pendingQueue struct {
+	mu    sync.Mutex
+	items []checkRequest
+	limit int
+}
+
+func newPendingQueue(limit int) *pendingQueue {
+	return &pendingQueue{limit: limit}
+}
+
+// push добавляет проверку в конец очереди; false, если очередь заполнена
+func (q *pendingQueue) push(p checkRequest) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) >= q.limit {
+		return false
+	}
+	q.items = append(q.items, p)
+	return true
+}
+
+// requeue возвращает необработанные проверки в начало очереди
+func (q *pendingQueue) requeue(items []checkRequest) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.items = append(items, q.items...)
+	if len(q.items) > q.limit {
+		q.items = q.items[:q.limit]
+	}
+}
+
+// drain забирает все проверки из очереди
+func (q *pendingQueue) drain() []checkRequest {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items := q.items
+	q.items = nil
+	return items
+}
+
+// runPending периодически повторяет отложенные проверки, пока бэкенд снова
+// не начнёт отвечать. Останавливается при отмене ctx.
+func (h *Handler) runPending(ctx context.Context) {
+	ticker := time.NewTicker(pendingRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.retryPending(ctx)
+		}
+	}
+}
+
+func (h *Handler) retryPending(ctx context.Context) {
+	items := h.pending.drain()
+
+	for i, p := range items {
+		if ctx.Err() != nil {
+			h.pending.requeue(items[i:])
+			return
+		}
+
+		p.deferred = true
+		response, err := h.check(ctx, &p)
+		if errors.Is(err, checker.ErrUnavailable) {
+			// Бэкенд всё ещё недоступен - ждём следующего тика
+			h.pending.requeue(items[i:])
+			return
+		}
+		if err != nil {
+			h.logger.Error("failed to check pending text", "error", err, "chat_id", p.chatID)
+			h.sendReply(p.chatID, p.replyTo, "❌ Не удалось проверить отложенный текст. Пожалуйста, отправьте его ещё раз.")
+			continue
+		}
+
+		h.logger.Info("pending text checked", "chat_id", p.chatID, "username", p.username)
+		h.sendCorrectionResults(p, response)
+	}
+}
@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"spell_bot/internal/checker"
+	"spell_bot/internal/entity"
+	"spell_bot/internal/storage/memory"
+)
+
+// unavailableChecker имитирует недоступный бэкенд проверки
+type unavailableChecker struct {
+	calls atomic.Int32
+}
+
+func (c *unavailableChecker) Check(context.Context, string, checker.Options) (*checker.CheckResponse, error) {
+	c.calls.Add(1)
+	return nil, checker.ErrUnavailable
+}
+
+func TestPendingRetriesDoNotDuplicateHistory(t *testing.T) {
+	ctx := context.Background()
+	api, _ := newTestAPI(t)
+	provider := &unavailableChecker{}
+	store := memory.NewStorage()
+	h := NewHandler(api, provider, store, testLogger, Options{})
+
+	req := checkRequest{chatID: 42, telegramID: 42, text: "текст", settings: entity.DefaultUserSettings(42, false)}
+	h.processTextCheck(ctx, req)
+	for i := 0; i < 3; i++ {
+		h.retryPending(ctx)
+	}
+
+	if got := provider.calls.Load(); got != 4 {
+		t.Fatalf("checker calls = %d, want 4", got)
+	}
+	count, err := store.CountChecks(ctx, 42)
+	if err != nil {
+		t.Fatalf("CountChecks: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("history rows = %d, want 1", count)
+	}
+	if items := h.pending.drain(); len(items) != 1 {
+		t.Errorf("pending = %d, want the text still queued", len(items))
+	}
+}
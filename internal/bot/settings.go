@@ -0,0 +1,148 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"spell_bot/internal/entity"
+	"spell_bot/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackSettings = "settings:"
+
+	settingFormat   = "format"
+	settingExplain  = "explain"
+	settingDiff     = "diff"
+	settingStrict   = "strict"
+	settingLanguage = "lang"
+)
+
+var outputFormatNames = map[string]string{
+	entity.OutputFormatCode:  "блок кода",
+	entity.OutputFormatPlain: "обычный текст",
+}
+
+var strictnessNames = map[string]string{
+	entity.StrictnessLenient: "мягкая",
+	entity.StrictnessNormal:  "обычная",
+	entity.StrictnessStrict:  "строгая",
+}
+
+var languageNames = map[string]string{
+	entity.LanguageRussian:   "русский",
+	entity.LanguageEnglish:   "английский",
+	entity.LanguageUkrainian: "украинский",
+}
+
+// Порядок значений при переключении по кругу
+var (
+	outputFormats = []string{entity.OutputFormatCode, entity.OutputFormatPlain}
+	strictnesses  = []string{entity.StrictnessLenient, entity.StrictnessNormal, entity.StrictnessStrict}
+	languages     = []string{entity.LanguageRussian, entity.LanguageEnglish, entity.LanguageUkrainian}
+)
+
+// userSettings возвращает настройки пользователя или настройки по умолчанию,
+// если пользователь их не менял или хранилище недоступно
+func (h *Handler) userSettings(ctx context.Context, telegramID int64) *entity.UserSettings {
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	settings, err := h.storage.GetUserSettings(dbCtx, telegramID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			h.logger.Error("failed to load user settings", "error", err, "telegram_id", telegramID)
+		}
+		return entity.DefaultUserSettings(telegramID, h.opts.DiffView)
+	}
+
+	return settings
+}
+
+// sendSettings отправляет меню настроек
+func (h *Handler) sendSettings(ctx context.Context, chatID, telegramID int64) {
+	text, markup := renderSettings(h.userSettings(ctx, telegramID))
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = markup
+	if _, err := h.bot.Send(msg); err != nil {
+		h.logger.Error("failed to send settings", "error", err, "chat_id", chatID)
+	}
+}
+
+// handleSettingsCallback переключает выбранную настройку и обновляет меню
+func (h *Handler) handleSettingsCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	settings := h.userSettings(ctx, query.From.ID)
+
+	switch strings.TrimPrefix(query.Data, callbackSettings) {
+	case settingFormat:
+		settings.OutputFormat = cycle(outputFormats, settings.OutputFormat)
+	case settingExplain:
+		settings.ShowExplanations = !settings.ShowExplanations
+	case settingDiff:
+		settings.DiffView = !settings.DiffView
+	case settingStrict:
+		settings.Strictness = cycle(strictnesses, settings.Strictness)
+	case settingLanguage:
+		settings.Language = cycle(languages, settings.Language)
+	default:
+		h.answerCallback(query.ID, "")
+		return
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := h.storage.SaveUserSettings(dbCtx, settings); err != nil {
+		h.logger.Error("failed to save user settings", "error", err, "telegram_id", query.From.ID)
+		h.answerCallback(query.ID, "Не удалось сохранить настройки")
+		return
+	}
+
+	h.answerCallback(query.ID, "Сохранено")
+	text, markup := renderSettings(settings)
+	h.editCallbackMessage(query, text, &markup)
+}
+
+func renderSettings(settings *entity.UserSettings) (string, tgbotapi.InlineKeyboardMarkup) {
+	text := "⚙️ <b>Настройки</b>\n\nНажмите на параметр, чтобы изменить его."
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		settingsRow("📋 Формат: "+outputFormatNames[settings.OutputFormat], settingFormat),
+		settingsRow("💡 Объяснения: "+onOff(settings.ShowExplanations), settingExplain),
+		settingsRow("🔍 Подсветка изменений: "+onOff(settings.DiffView), settingDiff),
+		settingsRow("🎯 Строгость: "+strictnessNames[settings.Strictness], settingStrict),
+		settingsRow("🌐 Язык: "+languageNames[settings.Language], settingLanguage),
+	)
+
+	return text, markup
+}
+
+func settingsRow(label, setting string) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("%s%s", callbackSettings, setting)),
+	)
+}
+
+func onOff(v bool) string {
+	if v {
+		return "вкл"
+	}
+	return "выкл"
+}
+
+// cycle возвращает значение, следующее за current, по кругу
+func cycle(values []string, current string) string {
+	for i, v := range values {
+		if v == current {
+			return values[(i+1)%len(values)]
+		}
+	}
+	return values[0]
+}
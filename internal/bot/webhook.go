@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// secretTokenHeader - заголовок, в котором Telegram передаёт secret_token из setWebhook
+	secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+	maxUpdateSize          = 1 << 20
+	webhookShutdownTimeout = 5 * time.Second
+)
+
+// WebhookConfig - настройки приёма обновлений через webhook
+type WebhookConfig struct {
+	// URL - публичный адрес, который регистрируется в Telegram; пустой URL
+	// пропускает регистрацию (например, для локальной отладки)
+	URL string
+	// ListenAddr - адрес встроенного HTTP-сервера
+	ListenAddr string
+	// Path - путь, на который Telegram присылает обновления
+	Path string
+	// Secret сверяется с заголовком X-Telegram-Bot-Api-Secret-Token
+	Secret string
+}
+
+// StartWebhook регистрирует webhook и принимает обновления встроенным
+// HTTP-сервером, передавая их в тот же пул воркеров, что и Start
+func (b *Bot) StartWebhook(ctx context.Context, cfg WebhookConfig) error {
+	const op = "bot.StartWebhook"
+
+	b.logger.Info("starting bot", "mode", "webhook", "listen_addr", cfg.ListenAddr, "path", cfg.Path)
+	b.started.Store(true)
+	defer close(b.loopDone)
+
+	ctx, cancel := b.runContext(ctx)
+	defer cancel()
+
+	if cfg.Secret == "" {
+		b.logger.Warn("webhook secret is not set, incoming requests are not verified")
+	}
+
+	if cfg.URL != "" {
+		if err := b.setWebhook(cfg); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, b.WebhookHandler(cfg.Secret))
+
+	server := &http.Server{
+		Addr:              cfg.ListenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		// Запросы наследуют ctx бота, поэтому Stop разблокирует ожидание очереди
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go b.handler.runPending(ctx)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("%s: %w", op, err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+	defer shutdownCancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// WebhookHandler принимает обновления Telegram по HTTP. Пустой secret
+// отключает проверку заголовка.
+func (b *Bot) WebhookHandler(secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if secret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(secretTokenHeader)), []byte(secret)) != 1 {
+			b.logger.Warn("webhook request with invalid secret token", "remote_addr", r.RemoteAddr)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxUpdateSize)
+		update, err := b.api.HandleUpdate(r)
+		if err != nil {
+			b.logger.Warn("failed to parse webhook update", "error", err)
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		b.lastPoll.Store(time.Now().UnixNano())
+
+		// Не-2xx ответ заставит Telegram повторить доставку позже
+		if err := b.dispatch(r.Context(), *update); err != nil {
+			b.logger.Warn("webhook update rejected", "update_id", update.UpdateID, "error", err)
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// setWebhook регистрирует webhook. WebhookConfig библиотеки не поддерживает
+// secret_token, поэтому параметры передаются напрямую.
+func (b *Bot) setWebhook(cfg WebhookConfig) error {
+	params := tgbotapi.Params{"url": cfg.URL}
+	params.AddNonEmpty("secret_token", cfg.Secret)
+
+	if _, err := b.api.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const startUpdate = `{
+	"update_id": 10,
+	"message": {
+		"message_id": 5,
+		"date": 1700000000,
+		"from": {"id": 42, "first_name": "Test"},
+		"chat": {"id": 42, "type": "private"},
+		"text": "/start",
+		"entities": [{"type": "bot_command", "offset": 0, "length": 6}]
+	}
+}`
+
+func TestWebhookHandlerRejectsInvalidSecret(t *testing.T) {
+	b, stub := newTestBot(t)
+	handler := b.WebhookHandler("secret")
+
+	for name, header := range map[string]string{"missing": "", "wrong": "other"} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(startUpdate))
+			if header != "" {
+				req.Header.Set(secretTokenHeader, header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+
+	if stats := b.pool.Stats(); stats.Queued+stats.InFlight != 0 {
+		t.Errorf("rejected update was dispatched: %+v", stats)
+	}
+	if _, ok := stub.find("sendMessage"); ok {
+		t.Error("bot replied to a rejected update")
+	}
+}
+
+func TestWebhookHandlerRejectsBadBody(t *testing.T) {
+	b, _ := newTestBot(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":`))
+	req.Header.Set(secretTokenHeader, "secret")
+	rec := httptest.NewRecorder()
+	b.WebhookHandler("secret").ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestWebhookHandlerDispatchesUpdate(t *testing.T) {
+	b, stub := newTestBot(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(startUpdate))
+	req.Header.Set(secretTokenHeader, "secret")
+	rec := httptest.NewRecorder()
+	b.WebhookHandler("secret").ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if b.LastPoll().IsZero() {
+		t.Error("LastPoll not updated")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := b.pool.Drain(ctx); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+
+	call, ok := stub.find("sendMessage")
+	if !ok {
+		t.Fatalf("update was not handled, calls: %v", stub.methods())
+	}
+	if call.params["chat_id"] != "42" {
+		t.Errorf("reply chat_id = %q, want 42", call.params["chat_id"])
+	}
+}
@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"spell_bot/internal/checker"
+	"spell_bot/internal/metrics"
+)
+
+var _ checker.Checker = (*Cache)(nil)
+
+// Tier - уровень кеша. Уровни опрашиваются по порядку, от быстрого к медленному.
+type Tier interface {
+	Name() string
+	// Get возвращает ответ по ключу; ok = false, если записи нет или она устарела
+	Get(ctx context.Context, key string) (resp *checker.CheckResponse, ok bool, err error)
+	Set(ctx context.Context, key string, resp *checker.CheckResponse, ttl time.Duration) error
+}
+
+// Cache - checker, отвечающий из кеша на уже проверенные тексты.
+// Ключ - хеш нормализованного текста, настроек проверки и версии
+// модели/промпта, поэтому смена модели или промпта сбрасывает кеш.
+// Кешируются только успешные ответы; Usage у ответа из кеша нулевой.
+type Cache struct {
+	inner   checker.Checker
+	version string
+	ttl     time.Duration
+	tiers   []Tier
+	metrics *metrics.Metrics
+}
+
+// New оборачивает inner кешем с уровнями tiers
+func New(inner checker.Checker, version string, ttl time.Duration, m *metrics.Metrics, tiers ...Tier) *Cache {
+	return &Cache{
+		inner:   inner,
+		version: version,
+		ttl:     ttl,
+		tiers:   tiers,
+		metrics: m,
+	}
+}
+
+func (c *Cache) Check(ctx context.Context, text string, opts checker.Options) (*checker.CheckResponse, error) {
+	lead, body, trail := normalize(text)
+	if body == "" {
+		return c.inner.Check(ctx, text, opts)
+	}
+
+	key := c.key(body, opts)
+
+	if resp, ok := c.lookup(ctx, key); ok {
+		return restore(resp, lead, trail), nil
+	}
+
+	resp, err := c.inner.Check(ctx, body, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(ctx, key, resp, len(c.tiers))
+
+	return restore(resp, lead, trail), nil
+}
+
+// Close закрывает уровни, которым это нужно
+func (c *Cache) Close() error {
+	if c == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, tier := range c.tiers {
+		if closer, ok := tier.(interface{ Close() error }); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lookup ищет ответ по уровням и дописывает найденное в более быстрые уровни
+func (c *Cache) lookup(ctx context.Context, key string) (*checker.CheckResponse, bool) {
+	for i, tier := range c.tiers {
+		resp, ok, err := tier.Get(ctx, key)
+		if err != nil {
+			c.metrics.StorageError("cache_" + tier.Name() + "_get")
+		}
+		c.metrics.CacheLookup(tier.Name(), ok)
+		if ok {
+			c.store(ctx, key, resp, i)
+			return resp, true
+		}
+	}
+	return nil, false
+}
+
+// store сохраняет ответ в первые n уровней
+func (c *Cache) store(ctx context.Context, key string, resp *checker.CheckResponse, n int) {
+	cached := *resp
+	cached.Usage = checker.Usage{}
+
+	for _, tier := range c.tiers[:n] {
+		if err := tier.Set(ctx, key, &cached, c.ttl); err != nil {
+			c.metrics.StorageError("cache_" + tier.Name() + "_set")
+		}
+	}
+}
+
+func (c *Cache) key(body string, opts checker.Options) string {
+	h := sha256.New()
+	for _, part := range []string{c.version, opts.Strictness, opts.Language, body} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalize отделяет пробельные символы по краям текста: тексты, отличающиеся
+// только ими, получают один ключ. Внутренние пробелы не трогаются, чтобы
+// смещения правок оставались верными.
+func normalize(text string) (lead, body, trail string) {
+	body = strings.TrimLeftFunc(text, unicode.IsSpace)
+	lead = text[:len(text)-len(body)]
+	trimmed := strings.TrimRightFunc(body, unicode.IsSpace)
+	trail = body[len(trimmed):]
+	return lead, trimmed, trail
+}
+
+// restore возвращает копию ответа для исходного текста с пробелами по краям
+func restore(resp *checker.CheckResponse, lead, trail string) *checker.CheckResponse {
+	out := *resp
+	out.Edits = slices.Clone(resp.Edits)
+	if lead == "" && trail == "" {
+		return &out
+	}
+
+	out.Edits = checker.ShiftEdits(resp.Edits, utf8.RuneCountInString(lead))
+	if out.CorrectedText != "" {
+		out.CorrectedText = lead + out.CorrectedText + trail
+	}
+	return &out
+}
@@ -0,0 +1,247 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"spell_bot/internal/checker"
+	"spell_bot/internal/storage/sqlite"
+)
+
+// countingChecker исправляет "превет" и считает обращения к нему
+type countingChecker struct {
+	calls int
+	texts []string
+	err   error
+}
+
+func (c *countingChecker) Check(_ context.Context, text string, _ checker.Options) (*checker.CheckResponse, error) {
+	c.calls++
+	c.texts = append(c.texts, text)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &checker.CheckResponse{
+		CorrectedText: "привет мир",
+		HasChanges:    true,
+		Edits:         []checker.Edit{{Start: 0, End: 6, Original: "превет", Replacement: "привет"}},
+		Model:         "gpt",
+		Usage:         checker.Usage{TotalTokens: 10},
+	}, nil
+}
+
+// fakeClock - управляемые часы для LRU
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLRU(capacity int) (*LRU, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	l := NewLRU(capacity)
+	l.now = clock.now
+	return l, clock
+}
+
+func newTestSQLite(t *testing.T, path string) *SQLite {
+	t.Helper()
+
+	s, err := NewSQLite(path)
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func check(t *testing.T, c *Cache, text string, opts checker.Options) *checker.CheckResponse {
+	t.Helper()
+
+	resp, err := c.Check(context.Background(), text, opts)
+	if err != nil {
+		t.Fatalf("Check(%q): %v", text, err)
+	}
+	return resp
+}
+
+func TestCacheHitAndMiss(t *testing.T) {
+	inner := &countingChecker{}
+	lru, _ := newTestLRU(10)
+	c := New(inner, "v1", time.Hour, nil, lru)
+
+	first := check(t, c, "превет мир", checker.Options{})
+	if first.Usage.TotalTokens != 10 {
+		t.Fatalf("miss: Usage = %+v, want provider usage", first.Usage)
+	}
+
+	second := check(t, c, "превет мир", checker.Options{})
+	if inner.calls != 1 {
+		t.Fatalf("inner called %d times, want 1", inner.calls)
+	}
+	if second.CorrectedText != "привет мир" || second.Model != "gpt" || second.Usage != (checker.Usage{}) {
+		t.Fatalf("hit = %+v, want cached response with zero usage", second)
+	}
+
+	// Другие настройки проверки - другой ключ
+	check(t, c, "превет мир", checker.Options{Strictness: "strict"})
+	if inner.calls != 2 {
+		t.Fatalf("inner called %d times, want 2 after options change", inner.calls)
+	}
+
+	// Новая версия модели или промпта не видит старых записей
+	New(inner, "v2", time.Hour, nil, lru).Check(context.Background(), "превет мир", checker.Options{})
+	if inner.calls != 3 {
+		t.Fatalf("inner called %d times, want 3 after version change", inner.calls)
+	}
+}
+
+func TestCacheDoesNotStoreErrors(t *testing.T) {
+	inner := &countingChecker{err: errors.New("backend down")}
+	lru, _ := newTestLRU(10)
+	c := New(inner, "v1", time.Hour, nil, lru)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Check(context.Background(), "превет мир", checker.Options{}); err == nil {
+			t.Fatal("Check: want error")
+		}
+	}
+	if inner.calls != 2 {
+		t.Fatalf("inner called %d times, want 2", inner.calls)
+	}
+}
+
+func TestCacheTTLExpiry(t *testing.T) {
+	inner := &countingChecker{}
+	lru, clock := newTestLRU(10)
+	c := New(inner, "v1", time.Hour, nil, lru)
+
+	check(t, c, "превет мир", checker.Options{})
+	clock.advance(59 * time.Minute)
+	check(t, c, "превет мир", checker.Options{})
+	if inner.calls != 1 {
+		t.Fatalf("inner called %d times before TTL, want 1", inner.calls)
+	}
+
+	clock.advance(time.Minute)
+	check(t, c, "превет мир", checker.Options{})
+	if inner.calls != 2 {
+		t.Fatalf("inner called %d times after TTL, want 2", inner.calls)
+	}
+}
+
+func TestCacheBackfillsFasterTier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	inner := &countingChecker{}
+
+	// Первый процесс заполняет постоянный уровень
+	warm, _ := newTestLRU(10)
+	check(t, New(inner, "v1", time.Hour, nil, warm, newTestSQLite(t, path)), "превет мир", checker.Options{})
+
+	// После перезапуска память пуста, ответ приходит из SQLite и копируется в LRU
+	lru, _ := newTestLRU(10)
+	persistent := newTestSQLite(t, path)
+	c := New(inner, "v1", time.Hour, nil, lru, persistent)
+
+	resp := check(t, c, "превет мир", checker.Options{})
+	if inner.calls != 1 {
+		t.Fatalf("inner called %d times, want 1", inner.calls)
+	}
+	if resp.Model != "gpt" || len(resp.Edits) != 1 {
+		t.Fatalf("response from SQLite = %+v, want model and edits preserved", resp)
+	}
+
+	if _, ok, _ := lru.Get(context.Background(), c.key("превет мир", checker.Options{})); !ok {
+		t.Fatal("response from SQLite was not copied to LRU")
+	}
+}
+
+func TestCacheRestoresSurroundingWhitespace(t *testing.T) {
+	inner := &countingChecker{}
+	lru, _ := newTestLRU(10)
+	c := New(inner, "v1", time.Hour, nil, lru)
+
+	check(t, c, "превет мир", checker.Options{})
+	resp := check(t, c, "\n  превет мир \n", checker.Options{})
+
+	if inner.calls != 1 {
+		t.Fatalf("inner called %d times, want whitespace-only difference to hit cache", inner.calls)
+	}
+	if resp.CorrectedText != "\n  привет мир \n" {
+		t.Fatalf("CorrectedText = %q, want surrounding whitespace restored", resp.CorrectedText)
+	}
+	want := []checker.Edit{{Start: 3, End: 9, Original: "превет", Replacement: "привет"}}
+	if !reflect.DeepEqual(resp.Edits, want) {
+		t.Fatalf("Edits = %+v, want %+v", resp.Edits, want)
+	}
+
+	// Ответ из кеша не портится сдвигом правок
+	again := check(t, c, "превет мир", checker.Options{})
+	if again.Edits[0].Start != 0 {
+		t.Fatalf("cached edit shifted to %d", again.Edits[0].Start)
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	lru, _ := newTestLRU(2)
+	resp := &checker.CheckResponse{}
+
+	lru.Set(ctx, "a", resp, time.Hour)
+	lru.Set(ctx, "b", resp, time.Hour)
+	lru.Get(ctx, "a")
+	lru.Set(ctx, "c", resp, time.Hour)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok, _ := lru.Get(ctx, key); ok != want {
+			t.Errorf("Get(%q) ok = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestSQLiteExpiry(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLite(t, filepath.Join(t.TempDir(), "cache.db"))
+	resp := &checker.CheckResponse{CorrectedText: "привет", Model: "gpt"}
+
+	if err := s.Set(ctx, "fresh", resp, time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.Set(ctx, "stale", resp, -time.Second); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	got, ok, err := s.Get(ctx, "fresh")
+	if err != nil || !ok || got.Model != "gpt" || got.CorrectedText != "привет" {
+		t.Fatalf("Get(fresh) = %+v, %v, %v", got, ok, err)
+	}
+	if _, ok, err := s.Get(ctx, "stale"); err != nil || ok {
+		t.Fatalf("Get(stale) ok = %v, err = %v; want miss", ok, err)
+	}
+}
+
+func TestSQLiteSharesFileWithStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bot.db")
+
+	storage, err := sqlite.NewStorage(path)
+	if err != nil {
+		t.Fatalf("sqlite.NewStorage: %v", err)
+	}
+	storage.Close()
+
+	newTestSQLite(t, path)
+
+	// Миграции хранилища по-прежнему считаются применёнными
+	pending, err := sqlite.PendingMigrations(context.Background(), path)
+	if err != nil || len(pending) != 0 {
+		t.Fatalf("PendingMigrations = %v, %v; want none", pending, err)
+	}
+	storage, err = sqlite.NewStorage(path)
+	if err != nil {
+		t.Fatalf("reopen storage: %v", err)
+	}
+	storage.Close()
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"spell_bot/internal/checker"
+)
+
+// LRU - уровень кеша в памяти с ограничением числа записей
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // от недавно использованных к давним
+	items    map[string]*list.Element
+	now      func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	resp      *checker.CheckResponse
+	expiresAt time.Time
+}
+
+// NewLRU создаёт кеш в памяти не более чем на capacity записей
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+func (l *LRU) Name() string {
+	return "memory"
+}
+
+func (l *LRU) Get(_ context.Context, key string) (*checker.CheckResponse, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := el.Value.(*lruEntry)
+	if !l.now().Before(entry.expiresAt) {
+		l.remove(el)
+		return nil, false, nil
+	}
+
+	l.order.MoveToFront(el)
+	return entry.resp, true, nil
+}
+
+func (l *LRU) Set(_ context.Context, key string, resp *checker.CheckResponse, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expiresAt := l.now().Add(ttl)
+
+	if el, ok := l.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.resp = resp
+		entry.expiresAt = expiresAt
+		l.order.MoveToFront(el)
+		return nil
+	}
+
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, resp: resp, expiresAt: expiresAt})
+
+	for l.order.Len() > l.capacity {
+		l.remove(l.order.Back())
+	}
+	return nil
+}
+
+func (l *LRU) remove(el *list.Element) {
+	l.order.Remove(el)
+	delete(l.items, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS check_cache (
+    key TEXT PRIMARY KEY,
+    response TEXT NOT NULL,
+    expires_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_check_cache_expires_at ON check_cache(expires_at);
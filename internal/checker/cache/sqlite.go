@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"spell_bot/internal/checker"
+	"spell_bot/internal/storage/migrate"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// versionTable хранит версию схемы кеша отдельно от schema_version основного
+// хранилища, поэтому кеш можно держать и в том же файле, что SQLITE_PATH
+const versionTable = "check_cache_schema_version"
+
+// SQLite - постоянный уровень кеша, переживающий перезапуски бота
+type SQLite struct {
+	db *sql.DB
+}
+
+// sqliteEntry - сериализованный ответ; Model у CheckResponse не попадает в JSON
+type sqliteEntry struct {
+	Response *checker.CheckResponse `json:"response"`
+	Model    string                 `json:"model"`
+}
+
+// NewSQLite открывает кеш в файле path и удаляет устаревшие записи
+func NewSQLite(path string) (*SQLite, error) {
+	const op = "cache.NewSQLite"
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	migrations, err := migrate.Load(migrationsFS, "migrations")
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx := context.Background()
+	if err := migrate.ApplyTable(ctx, db, migrate.SQLite, versionTable, migrations); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM check_cache WHERE expires_at <= ?`, time.Now().Unix()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &SQLite{db: db}, nil
+}
+
+func (s *SQLite) Name() string {
+	return "sqlite"
+}
+
+func (s *SQLite) Get(ctx context.Context, key string) (*checker.CheckResponse, bool, error) {
+	const op = "cache.SQLite.Get"
+
+	var data string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT response FROM check_cache WHERE key = ? AND expires_at > ?`,
+		key, time.Now().Unix(),
+	).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var entry sqliteEntry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil || entry.Response == nil {
+		return nil, false, fmt.Errorf("%s: invalid cache entry: %w", op, err)
+	}
+	entry.Response.Model = entry.Model
+
+	return entry.Response, true, nil
+}
+
+func (s *SQLite) Set(ctx context.Context, key string, resp *checker.CheckResponse, ttl time.Duration) error {
+	const op = "cache.SQLite.Set"
+
+	data, err := json.Marshal(sqliteEntry{Response: resp, Model: resp.Model})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO check_cache (key, response, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET response = excluded.response, expires_at = excluded.expires_at`,
+		key, string(data), time.Now().Add(ttl).Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
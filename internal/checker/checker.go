@@ -0,0 +1,107 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Checker проверяет текст на орфографические, пунктуационные и грамматические ошибки
+type Checker interface {
+	Check(ctx context.Context, text string, opts Options) (*CheckResponse, error)
+}
+
+// Options - параметры проверки, выбранные пользователем.
+// Пустые поля означают поведение провайдера по умолчанию.
+type Options struct {
+	Strictness string // entity.Strictness*
+	Language   string // entity.Language*
+}
+
+type CheckResponse struct {
+	CorrectedText string `json:"corrected_text"`
+	HasChanges    bool   `json:"has_changes"`
+	Explanation   string `json:"explanation"`
+	Edits         []Edit `json:"edits"`
+
+	// Заполняются провайдером, а не моделью
+	Model string `json:"-"`
+	Usage Usage  `json:"-"`
+}
+
+// Usage - расход токенов на проверку
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Add суммирует расход токенов
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+}
+
+// Factory создаёт экземпляр Checker для зарегистрированного провайдера
+type Factory func() (Checker, error)
+
+// Registry хранит фабрики провайдеров проверки по имени
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]Factory),
+	}
+}
+
+// Register регистрирует фабрику провайдера; повторная регистрация имени заменяет предыдущую
+func (r *Registry) Register(name string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.factories[name] = factory
+}
+
+// New создаёт Checker выбранного провайдера
+func (r *Registry) New(name string) (Checker, error) {
+	const op = "checker.Registry.New"
+
+	r.mu.RLock()
+	factory, ok := r.factories[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown provider %q (available: %v)", op, name, r.Names())
+	}
+
+	c, err := factory()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", op, name, err)
+	}
+
+	return c, nil
+}
+
+// Names возвращает отсортированный список зарегистрированных провайдеров
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ErrUnavailable возвращается провайдером, когда бэкенд временно недоступен
+// и запрос был отклонён без обращения к нему (например, открыт circuit breaker)
+var ErrUnavailable = errors.New("checker temporarily unavailable")
@@ -0,0 +1,135 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Chunked делит длинные тексты на фрагменты, проверяет их параллельно
+// (не более parallelism одновременно) и собирает результат в исходном порядке.
+type Chunked struct {
+	inner       Checker
+	maxRunes    int
+	parallelism int
+}
+
+var _ Checker = (*Chunked)(nil)
+
+func NewChunked(inner Checker, maxRunes, parallelism int) *Chunked {
+	return &Chunked{
+		inner:       inner,
+		maxRunes:    maxRunes,
+		parallelism: max(parallelism, 1),
+	}
+}
+
+func (c *Chunked) Check(ctx context.Context, text string, opts Options) (*CheckResponse, error) {
+	chunks := Split(text, c.maxRunes)
+	if len(chunks) == 1 {
+		return c.inner.Check(ctx, text, opts)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		sem       = make(chan struct{}, c.parallelism)
+		responses = make([]*CheckResponse, len(chunks))
+		errOnce   sync.Once
+		firstErr  error
+	)
+
+	for i, chunk := range chunks {
+		if chunk.Body == "" {
+			responses[i] = &CheckResponse{}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			resp, err := c.inner.Check(ctx, chunk.Body, opts)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), err)
+					cancel()
+				})
+				return
+			}
+			responses[i] = resp
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return merge(chunks, responses), nil
+}
+
+// Rounds возвращает число последовательных волн запросов при проверке text:
+// одновременно проверяется не более parallelism фрагментов
+func (c *Chunked) Rounds(text string) int {
+	chunks := 0
+	for _, chunk := range Split(text, c.maxRunes) {
+		if chunk.Body != "" {
+			chunks++
+		}
+	}
+	return max((chunks+c.parallelism-1)/c.parallelism, 1)
+}
+
+// merge склеивает ответы по фрагментам в один CheckResponse
+func merge(chunks []Chunk, responses []*CheckResponse) *CheckResponse {
+	var (
+		result       CheckResponse
+		bodies       = make([]string, len(chunks))
+		explanations []string
+		offset       int
+	)
+
+	for i, resp := range responses {
+		bodies[i] = chunks[i].Body
+		bodyOffset := offset + utf8.RuneCountInString(chunks[i].Lead)
+		offset = bodyOffset + utf8.RuneCountInString(chunks[i].Body) + utf8.RuneCountInString(chunks[i].Trail)
+
+		result.Usage.Add(resp.Usage)
+		if result.Model == "" {
+			result.Model = resp.Model
+		}
+		if !resp.HasChanges {
+			continue
+		}
+
+		result.Edits = append(result.Edits, ShiftEdits(resp.Edits, bodyOffset)...)
+
+		result.HasChanges = true
+		if resp.CorrectedText != "" {
+			bodies[i] = strings.TrimSpace(resp.CorrectedText)
+		}
+		if e := strings.TrimSpace(resp.Explanation); e != "" {
+			explanations = append(explanations, e)
+		}
+	}
+
+	result.CorrectedText = Join(chunks, bodies)
+	result.Explanation = strings.Join(explanations, "\n")
+
+	return &result
+}
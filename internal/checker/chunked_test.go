@@ -0,0 +1,128 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestChunkedRounds(t *testing.T) {
+	c := NewChunked(nil, 100, 3)
+
+	paragraph := strings.Repeat("слово ", 15) + "\n\n"
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 1},
+		{"короткий текст", 1},
+		{strings.Repeat(paragraph, 3), 1},
+		{strings.Repeat(paragraph, 17), 6},
+	}
+	for _, tt := range tests {
+		if got := c.Rounds(tt.text); got != tt.want {
+			t.Errorf("Rounds(%d runes) = %d, want %d", len([]rune(tt.text)), got, tt.want)
+		}
+	}
+}
+
+// chunkChecker помечает каждый фрагмент и добавляет правку в его начало
+type chunkChecker struct {
+	delay func(text string) time.Duration
+	fail  string // фрагмент, проверка которого завершается ошибкой
+
+	mu       sync.Mutex
+	canceled int
+}
+
+func (c *chunkChecker) Check(ctx context.Context, text string, _ Options) (*CheckResponse, error) {
+	if text == c.fail {
+		// Остальные фрагменты успевают начать проверку
+		time.Sleep(20 * time.Millisecond)
+		return nil, errors.New("backend error")
+	}
+	if c.delay != nil {
+		select {
+		case <-time.After(c.delay(text)):
+		case <-ctx.Done():
+			c.mu.Lock()
+			c.canceled++
+			c.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+
+	first, _ := utf8.DecodeRuneInString(text)
+	return &CheckResponse{
+		CorrectedText: "[" + text + "]",
+		HasChanges:    true,
+		Explanation:   "fixed " + string(first),
+		Edits:         []Edit{{Start: 0, End: 1, Original: string(first), Replacement: "[" + string(first)}},
+		Usage:         Usage{TotalTokens: 1},
+	}, nil
+}
+
+func TestChunkedKeepsOrderAndShiftsEdits(t *testing.T) {
+	paragraphs := []string{"Альфа.", "Бета.", "Гамма.", "Дельта.", "Эпсилон."}
+	text := "  " + strings.Join(paragraphs, "\n\n") + "\n"
+
+	// Первые фрагменты отвечают последними
+	inner := &chunkChecker{delay: func(s string) time.Duration {
+		return time.Duration(10-len([]rune(s))) * 3 * time.Millisecond
+	}}
+	resp, err := NewChunked(inner, 8, 3).Check(context.Background(), text, Options{})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	want := "  [" + strings.Join(paragraphs, "]\n\n[") + "]\n"
+	if resp.CorrectedText != want {
+		t.Errorf("CorrectedText = %q, want %q", resp.CorrectedText, want)
+	}
+	if resp.Usage.TotalTokens != len(paragraphs) {
+		t.Errorf("TotalTokens = %d, want %d", resp.Usage.TotalTokens, len(paragraphs))
+	}
+	if len(resp.Edits) != len(paragraphs) {
+		t.Fatalf("edits = %d, want %d", len(resp.Edits), len(paragraphs))
+	}
+
+	runes := []rune(text)
+	for i, e := range resp.Edits {
+		if got := string(runes[e.Start:e.End]); got != e.Original {
+			t.Errorf("edit %d points at %q, want %q", i, got, e.Original)
+		}
+		if e.Original != string([]rune(paragraphs[i])[0]) {
+			t.Errorf("edit %d is for %q, want paragraph %q", i, e.Original, paragraphs[i])
+		}
+	}
+	if lines := strings.Split(resp.Explanation, "\n"); len(lines) != len(paragraphs) || lines[0] != "fixed А" {
+		t.Errorf("Explanation = %q", resp.Explanation)
+	}
+}
+
+func TestChunkedCancelsSiblingsOnError(t *testing.T) {
+	text := "Первый.\n\nВторой.\n\nТретий."
+	inner := &chunkChecker{
+		fail:  "Первый.",
+		delay: func(string) time.Duration { return 5 * time.Second },
+	}
+
+	start := time.Now()
+	_, err := NewChunked(inner, 10, 3).Check(context.Background(), text, Options{})
+	if err == nil || !strings.Contains(err.Error(), "chunk 1/3") {
+		t.Fatalf("err = %v, want chunk 1/3 error", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Check waited for sibling chunks")
+	}
+
+	inner.mu.Lock()
+	defer inner.mu.Unlock()
+	if inner.canceled != 2 {
+		t.Errorf("canceled = %d, want 2", inner.canceled)
+	}
+}
@@ -0,0 +1,117 @@
+package checker
+
+import (
+	"sort"
+	"strings"
+)
+
+// Категории исправлений
+const (
+	CategorySpelling    = "spelling"
+	CategoryPunctuation = "punctuation"
+	CategoryGrammar     = "grammar"
+	CategoryStyle       = "style"
+	CategoryOther       = "other"
+)
+
+// Edit - одно исправление в исходном тексте. Start и End - смещения в символах
+// (рунах) исходного текста, End не включается; при Start == End это вставка.
+type Edit struct {
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	Original    string `json:"original"`
+	Replacement string `json:"replacement"`
+	Category    string `json:"category"`
+	Rationale   string `json:"rationale"`
+}
+
+// ValidateEdits сверяет исправления с исходным текстом: уточняет смещения,
+// если модель ошиблась, но фрагмент Original в тексте есть, отбрасывает
+// неподтверждённые, пустые и пересекающиеся правки и сортирует их по позиции.
+func ValidateEdits(text string, edits []Edit) []Edit {
+	runes := []rune(text)
+
+	valid := make([]Edit, 0, len(edits))
+	for _, e := range edits {
+		if e.Original == e.Replacement {
+			continue
+		}
+
+		start, ok := locate(runes, e)
+		if !ok {
+			continue
+		}
+		e.Start = start
+		e.End = start + len([]rune(e.Original))
+		e.Category = normalizeCategory(e.Category)
+		valid = append(valid, e)
+	}
+
+	sort.SliceStable(valid, func(i, j int) bool {
+		return valid[i].Start < valid[j].Start
+	})
+
+	result := valid[:0]
+	for _, e := range valid {
+		if len(result) > 0 {
+			prev := result[len(result)-1]
+			// Пересечение фрагментов или две вставки в одну позицию - оставляем первую правку
+			if e.Start < prev.End || (e.Start == prev.Start && e.Start == e.End && prev.Start == prev.End) {
+				continue
+			}
+		}
+		result = append(result, e)
+	}
+
+	return result
+}
+
+// ShiftEdits сдвигает смещения правок на offset символов
+func ShiftEdits(edits []Edit, offset int) []Edit {
+	shifted := make([]Edit, len(edits))
+	for i, e := range edits {
+		e.Start += offset
+		e.End += offset
+		shifted[i] = e
+	}
+	return shifted
+}
+
+// locate находит позицию фрагмента e.Original в тексте, ближайшую к e.Start
+func locate(runes []rune, e Edit) (int, bool) {
+	original := []rune(e.Original)
+
+	if e.Start >= 0 && e.Start+len(original) <= len(runes) && string(runes[e.Start:e.Start+len(original)]) == e.Original {
+		return e.Start, true
+	}
+	if len(original) == 0 {
+		return 0, false
+	}
+
+	best, found := 0, false
+	for i := 0; i+len(original) <= len(runes); i++ {
+		if string(runes[i:i+len(original)]) != e.Original {
+			continue
+		}
+		if !found || abs(i-e.Start) < abs(best-e.Start) {
+			best, found = i, true
+		}
+	}
+	return best, found
+}
+
+func normalizeCategory(category string) string {
+	switch c := strings.ToLower(strings.TrimSpace(category)); c {
+	case CategorySpelling, CategoryPunctuation, CategoryGrammar, CategoryStyle:
+		return c
+	default:
+		return CategoryOther
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,97 @@
+package checker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateEdits(t *testing.T) {
+	text := "Он пришол домой, и пришол снова."
+
+	tests := []struct {
+		name  string
+		edits []Edit
+		want  []Edit
+	}{
+		{
+			name:  "correct offset",
+			edits: []Edit{{Start: 3, End: 9, Original: "пришол", Replacement: "пришёл", Category: "spelling"}},
+			want:  []Edit{{Start: 3, End: 9, Original: "пришол", Replacement: "пришёл", Category: "spelling"}},
+		},
+		{
+			name:  "wrong offset moved to the nearest occurrence",
+			edits: []Edit{{Start: 22, End: 28, Original: "пришол", Replacement: "пришёл", Category: "spelling"}},
+			want:  []Edit{{Start: 19, End: 25, Original: "пришол", Replacement: "пришёл", Category: "spelling"}},
+		},
+		{
+			name:  "missing original dropped",
+			edits: []Edit{{Start: 3, End: 9, Original: "ушол", Replacement: "ушёл", Category: "spelling"}},
+			want:  []Edit{},
+		},
+		{
+			name:  "no-op dropped",
+			edits: []Edit{{Start: 0, End: 2, Original: "Он", Replacement: "Он"}},
+			want:  []Edit{},
+		},
+		{
+			name: "overlapping edit dropped",
+			edits: []Edit{
+				{Start: 3, End: 9, Original: "пришол", Replacement: "пришёл", Category: "spelling"},
+				{Start: 3, End: 15, Original: "пришол домой", Replacement: "пришёл домой", Category: "spelling"},
+			},
+			want: []Edit{{Start: 3, End: 9, Original: "пришол", Replacement: "пришёл", Category: "spelling"}},
+		},
+		{
+			name: "second insertion at the same position dropped",
+			edits: []Edit{
+				{Start: 15, End: 15, Original: "", Replacement: ",", Category: "punctuation"},
+				{Start: 15, End: 15, Original: "", Replacement: ";", Category: "punctuation"},
+			},
+			want: []Edit{{Start: 15, End: 15, Original: "", Replacement: ",", Category: "punctuation"}},
+		},
+		{
+			name:  "unknown category normalized",
+			edits: []Edit{{Start: 3, End: 9, Original: "пришол", Replacement: "пришёл", Category: " Orthography "}},
+			want:  []Edit{{Start: 3, End: 9, Original: "пришол", Replacement: "пришёл", Category: CategoryOther}},
+		},
+		{
+			name:  "category case normalized",
+			edits: []Edit{{Start: 3, End: 9, Original: "пришол", Replacement: "пришёл", Category: "Spelling"}},
+			want:  []Edit{{Start: 3, End: 9, Original: "пришол", Replacement: "пришёл", Category: CategorySpelling}},
+		},
+		{
+			name: "sorted by position",
+			edits: []Edit{
+				{Start: 19, End: 25, Original: "пришол", Replacement: "пришёл", Category: "spelling"},
+				{Start: 0, End: 2, Original: "Он", Replacement: "Она", Category: "grammar"},
+				{Start: 15, End: 17, Original: ", ", Replacement: " ", Category: "punctuation"},
+			},
+			want: []Edit{
+				{Start: 0, End: 2, Original: "Он", Replacement: "Она", Category: "grammar"},
+				{Start: 15, End: 17, Original: ", ", Replacement: " ", Category: "punctuation"},
+				{Start: 19, End: 25, Original: "пришол", Replacement: "пришёл", Category: "spelling"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateEdits(text, tt.edits)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateEdits = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShiftEdits(t *testing.T) {
+	edits := []Edit{{Start: 1, End: 3}}
+	got := ShiftEdits(edits, 10)
+
+	if got[0].Start != 11 || got[0].End != 13 {
+		t.Errorf("ShiftEdits = %+v", got)
+	}
+	if edits[0].Start != 1 {
+		t.Error("ShiftEdits modified its input")
+	}
+}
@@ -0,0 +1,22 @@
+package checker
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// Заполнители заменяют в тексте фрагменты, которые модель не должна менять
+// (код, ссылки, упоминания). Провайдер обязан вернуть их без изменений.
+
+// PlaceholderPattern находит заполнители в тексте
+var PlaceholderPattern = regexp.MustCompile(`⟦\d+⟧`)
+
+// Placeholder возвращает заполнитель с номером n
+func Placeholder(n int) string {
+	return "⟦" + strconv.Itoa(n) + "⟧"
+}
+
+// HasPlaceholders сообщает, содержит ли текст заполнители
+func HasPlaceholders(text string) bool {
+	return PlaceholderPattern.MatchString(text)
+}
@@ -0,0 +1,181 @@
+package checker
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Chunk - фрагмент исходного текста. Lead и Trail - пробельные символы вокруг
+// Body; склейка Lead+Body+Trail всех фрагментов по порядку даёт исходный текст.
+type Chunk struct {
+	Lead  string
+	Body  string
+	Trail string
+}
+
+// Split делит текст на фрагменты не длиннее maxRunes символов, предпочитая
+// границы абзацев, затем предложений, затем слов.
+func Split(text string, maxRunes int) []Chunk {
+	if maxRunes <= 0 || utf8.RuneCountInString(text) <= maxRunes {
+		return []Chunk{newChunk(text)}
+	}
+
+	var pieces []string
+	for _, paragraph := range splitKeep(text, paragraphBoundary) {
+		pieces = append(pieces, splitPiece(paragraph, maxRunes)...)
+	}
+
+	var (
+		chunks  []Chunk
+		current strings.Builder
+		size    int
+	)
+	for _, piece := range pieces {
+		n := utf8.RuneCountInString(piece)
+		if size > 0 && size+n > maxRunes {
+			chunks = append(chunks, newChunk(current.String()))
+			current.Reset()
+			size = 0
+		}
+		current.WriteString(piece)
+		size += n
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, newChunk(current.String()))
+	}
+
+	return chunks
+}
+
+// Join склеивает фрагменты обратно, подставляя исправленные тела
+func Join(chunks []Chunk, bodies []string) string {
+	var b strings.Builder
+	for i, c := range chunks {
+		b.WriteString(c.Lead)
+		b.WriteString(bodies[i])
+		b.WriteString(c.Trail)
+	}
+	return b.String()
+}
+
+func newChunk(s string) Chunk {
+	body := strings.TrimLeftFunc(s, unicode.IsSpace)
+	lead := s[:len(s)-len(body)]
+	trimmed := strings.TrimRightFunc(body, unicode.IsSpace)
+	return Chunk{
+		Lead:  lead,
+		Body:  trimmed,
+		Trail: body[len(trimmed):],
+	}
+}
+
+// splitPiece дробит слишком длинный абзац по предложениям, словам и, в крайнем случае, символам
+func splitPiece(piece string, maxRunes int) []string {
+	if utf8.RuneCountInString(piece) <= maxRunes {
+		return []string{piece}
+	}
+
+	for _, boundary := range []func(string) int{sentenceBoundary, wordBoundary} {
+		parts := splitKeep(piece, boundary)
+		if len(parts) > 1 {
+			var out []string
+			for _, p := range parts {
+				out = append(out, splitPiece(p, maxRunes)...)
+			}
+			return out
+		}
+	}
+
+	var out []string
+	runes := []rune(piece)
+	for len(runes) > maxRunes {
+		out = append(out, string(runes[:maxRunes]))
+		runes = runes[maxRunes:]
+	}
+	return append(out, string(runes))
+}
+
+// splitKeep режет s после каждой границы, найденной next, сохраняя все символы.
+// next возвращает длину префикса до конца границы или -1.
+func splitKeep(s string, next func(string) int) []string {
+	var parts []string
+	for s != "" {
+		i := next(s)
+		if i <= 0 || i >= len(s) {
+			break
+		}
+		parts = append(parts, s[:i])
+		s = s[i:]
+	}
+	if s != "" {
+		parts = append(parts, s)
+	}
+	return parts
+}
+
+// paragraphBoundary находит конец первой пустой строки (двух и более переводов строки подряд)
+func paragraphBoundary(s string) int {
+	i := strings.Index(s, "\n")
+	for i >= 0 {
+		j := i + 1
+		for j < len(s) && (s[j] == ' ' || s[j] == '\t' || s[j] == '\r') {
+			j++
+		}
+		if j < len(s) && s[j] == '\n' {
+			for j < len(s) && unicode.IsSpace(rune(s[j])) {
+				j++
+			}
+			return j
+		}
+		next := strings.Index(s[i+1:], "\n")
+		if next < 0 {
+			return -1
+		}
+		i += 1 + next
+	}
+	return -1
+}
+
+// sentenceBoundary находит конец первого предложения вместе с последующими пробелами
+func sentenceBoundary(s string) int {
+	for i, r := range s {
+		if r != '.' && r != '!' && r != '?' && r != '…' && r != '\n' {
+			continue
+		}
+		j := i + utf8.RuneLen(r)
+		// Пропускаем многоточия, "?!" и закрывающие кавычки/скобки
+		for j < len(s) {
+			c, size := utf8.DecodeRuneInString(s[j:])
+			if !strings.ContainsRune(".!?…»\")", c) {
+				break
+			}
+			j += size
+		}
+		k := j
+		for k < len(s) {
+			c, size := utf8.DecodeRuneInString(s[k:])
+			if !unicode.IsSpace(c) {
+				break
+			}
+			k += size
+		}
+		if k > j {
+			return k
+		}
+	}
+	return -1
+}
+
+// wordBoundary находит конец первого слова вместе с последующими пробелами
+func wordBoundary(s string) int {
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i < 0 {
+		return -1
+	}
+	j := strings.IndexFunc(s[i:], func(r rune) bool { return !unicode.IsSpace(r) })
+	if j < 0 {
+		return -1
+	}
+	return i + j
+}
@@ -0,0 +1,78 @@
+package checker
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func bodies(chunks []Chunk) []string {
+	out := make([]string, len(chunks))
+	for i, c := range chunks {
+		out[i] = c.Body
+	}
+	return out
+}
+
+func TestSplitJoinRoundTrip(t *testing.T) {
+	texts := []string{
+		"",
+		"   ",
+		"Короткий текст.",
+		"  Пробелы вокруг  \n",
+		strings.Repeat("Первое предложение абзаца. Второе предложение!\n\n", 20),
+		strings.Repeat("слово ", 300),
+		"\n\n" + strings.Repeat("Абзац с переносом\nстроки.\n \n", 15) + "\n",
+		strings.Repeat("оченьдлинноесловобезпробелов", 20),
+	}
+
+	for _, text := range texts {
+		chunks := Split(text, 100)
+		if got := Join(chunks, bodies(chunks)); got != text {
+			t.Errorf("Join(Split(%.20q)) = %.20q", text, got)
+		}
+		for _, c := range chunks {
+			if n := utf8.RuneCountInString(c.Lead + c.Body + c.Trail); n > 100 {
+				t.Errorf("chunk of %d runes exceeds the limit", n)
+			}
+		}
+	}
+}
+
+func TestSplitPrefersParagraphs(t *testing.T) {
+	first := "Первый абзац. В нём два предложения."
+	second := "Второй абзац. Тоже два предложения."
+	chunks := Split(first+"\n\n"+second, len([]rune(first))+10)
+
+	got := bodies(chunks)
+	if len(got) != 2 || got[0] != first || got[1] != second {
+		t.Errorf("bodies = %q, want the two paragraphs", got)
+	}
+}
+
+func TestSplitPrefersSentencesOverWords(t *testing.T) {
+	first := "Первое предложение довольно длинное."
+	second := "Второе предложение тоже длинное!"
+	chunks := Split(first+" "+second, len([]rune(first))+5)
+
+	got := bodies(chunks)
+	if len(got) != 2 || got[0] != first || got[1] != second {
+		t.Errorf("bodies = %q, want the two sentences", got)
+	}
+}
+
+func TestSplitFallsBackToWords(t *testing.T) {
+	text := strings.TrimSpace(strings.Repeat("слово ", 40))
+	chunks := Split(text, 20)
+
+	if len(chunks) < 2 {
+		t.Fatalf("chunks = %d, want several", len(chunks))
+	}
+	for _, body := range bodies(chunks) {
+		for _, word := range strings.Fields(body) {
+			if word != "слово" {
+				t.Errorf("word cut: %q", word)
+			}
+		}
+	}
+}
@@ -1,15 +1,85 @@
 package config
 
 import (
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
 type Config struct {
-	TelegramToken  string `envconfig:"TELEGRAM_BOT_TOKEN"`
-	DeepSeekAPIKey string `envconfig:"DEEPSEEK_API_KEY"`
-	DebugMode      bool   `envconfig:"DEBUG_MODE"`
+	TelegramToken   string `envconfig:"TELEGRAM_BOT_TOKEN"`
+	DeepSeekAPIKey  string `envconfig:"DEEPSEEK_API_KEY"`
+	DeepSeekBaseURL string `envconfig:"DEEPSEEK_BASE_URL"`
+	DebugMode       bool   `envconfig:"DEBUG_MODE"`
+
+	// DiffView - значение настройки подсветки изменений по умолчанию для новых пользователей
+	DiffView bool `envconfig:"DIFF_VIEW" default:"true"`
+
+	// CheckerProvider выбирает бэкенд проверки текста из checker.Registry
+	CheckerProvider string `envconfig:"CHECKER_PROVIDER" default:"deepseek"`
+
+	// Повторные попытки запросов к LLM
+	CheckerMaxAttempts    int           `envconfig:"CHECKER_MAX_ATTEMPTS" default:"3"`
+	CheckerRetryBaseDelay time.Duration `envconfig:"CHECKER_RETRY_BASE_DELAY" default:"500ms"`
+	CheckerRetryMaxDelay  time.Duration `envconfig:"CHECKER_RETRY_MAX_DELAY" default:"10s"`
+
+	// Длинные тексты проверяются фрагментами не длиннее CHECKER_CHUNK_SIZE символов
+	CheckerChunkSize        int `envconfig:"CHECKER_CHUNK_SIZE" default:"3000"`
+	CheckerChunkParallelism int `envconfig:"CHECKER_CHUNK_PARALLELISM" default:"3"`
+
+	// Кеш ответов: CHECKER_CACHE_SIZE=0 отключает кеш в памяти,
+	// CHECKER_CACHE_SQLITE_PATH - файл постоянного уровня (может совпадать
+	// с SQLITE_PATH), пустой путь отключает его
+	CheckerCacheSize       int           `envconfig:"CHECKER_CACHE_SIZE" default:"1000"`
+	CheckerCacheTTL        time.Duration `envconfig:"CHECKER_CACHE_TTL" default:"24h"`
+	CheckerCacheSQLitePath string        `envconfig:"CHECKER_CACHE_SQLITE_PATH"`
+
+	// Circuit breaker: 0 в CHECKER_BREAKER_THRESHOLD отключает его
+	CheckerBreakerThreshold int           `envconfig:"CHECKER_BREAKER_THRESHOLD" default:"5"`
+	CheckerBreakerCooldown  time.Duration `envconfig:"CHECKER_BREAKER_COOLDOWN" default:"30s"`
+
+	// OpenAI-совместимый провайдер (CHECKER_PROVIDER=openai)
+	OpenAIBaseURL string            `envconfig:"OPENAI_BASE_URL"`
+	OpenAIModel   string            `envconfig:"OPENAI_MODEL"`
+	OpenAIAPIKey  string            `envconfig:"OPENAI_API_KEY"`
+	OpenAIHeaders map[string]string `envconfig:"OPENAI_HEADERS"` // формат: Key1:Value1,Key2:Value2
+
+	// BotMode - способ получения обновлений: polling или webhook
+	BotMode           string `envconfig:"BOT_MODE" default:"polling"`
+	WebhookURL        string `envconfig:"WEBHOOK_URL"`
+	WebhookListenAddr string `envconfig:"WEBHOOK_LISTEN_ADDR" default:":8443"`
+	WebhookPath       string `envconfig:"WEBHOOK_PATH" default:"/webhook"`
+	WebhookSecret     string `envconfig:"WEBHOOK_SECRET"`
+
+	// MetricsAddr - адрес HTTP-сервера с /metrics, /healthz и /readyz; пустое значение отключает сервер
+	MetricsAddr string `envconfig:"METRICS_ADDR" default:":2112"`
+	// Бот считается неживым, если getUpdates не отвечал дольше HEALTH_POLL_STALE_AFTER
+	HealthPollStaleAfter time.Duration `envconfig:"HEALTH_POLL_STALE_AFTER" default:"3m"`
+	// Бот не готов, если доля успешных недавних запросов к LLM ниже HEALTH_MIN_SUCCESS_RATE
+	HealthMinSuccessRate float64 `envconfig:"HEALTH_MIN_SUCCESS_RATE" default:"0.5"`
+
+	// Пул воркеров обработки обновлений
+	Workers         int           `envconfig:"WORKERS" default:"8"`
+	QueueSize       int           `envconfig:"QUEUE_SIZE" default:"256"`
+	ShutdownTimeout time.Duration `envconfig:"SHUTDOWN_TIMEOUT" default:"30s"`
+
+	// Ограничения на пользователя; 0 отключает соответствующий лимит
+	RateLimitPerMinute float64 `envconfig:"RATE_LIMIT_PER_MINUTE" default:"10"`
+	RateLimitBurst     int     `envconfig:"RATE_LIMIT_BURST" default:"3"`
+	DailyRequestQuota  int     `envconfig:"DAILY_REQUEST_QUOTA" default:"200"`
+	DailyCharQuota     int     `envconfig:"DAILY_CHAR_QUOTA" default:"200000"`
+	// AdminIDs - Telegram ID администраторов: без лимитов, доступна команда /limits
+	AdminIDs []int64 `envconfig:"ADMIN_IDS"`
+
+	// StorageDriver выбирает бэкенд хранилища: sqlite, postgres или memory
+	StorageDriver string `envconfig:"STORAGE_DRIVER" default:"sqlite"`
 
 	SQLitePath string `envconfig:"SQLITE_PATH"`
+
+	PostgresDSN             string        `envconfig:"POSTGRES_DSN"`
+	PostgresMaxOpenConns    int           `envconfig:"POSTGRES_MAX_OPEN_CONNS" default:"10"`
+	PostgresMaxIdleConns    int           `envconfig:"POSTGRES_MAX_IDLE_CONNS" default:"5"`
+	PostgresConnMaxLifetime time.Duration `envconfig:"POSTGRES_CONN_MAX_LIFETIME" default:"30m"`
 }
 
 func Load() (*Config, error) {
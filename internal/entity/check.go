@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// Статусы проверки текста
+const (
+	CheckStatusOK          = "ok"          // проверка выполнена
+	CheckStatusFailed      = "failed"      // бэкенд вернул ошибку
+	CheckStatusUnavailable = "unavailable" // бэкенд недоступен, текст отложен
+)
+
+type Check struct {
+	ID               int64  // DB primary key (автоинкремент)
+	TelegramID       int64  // Telegram User ID автора текста
+	ChatID           int64  // Telegram Chat ID
+	OriginalText     string // Исходный текст
+	CorrectedText    string // Исправленный текст (пустой при ошибке)
+	Explanation      string // Объяснение исправлений от модели
+	Model            string // Модель, выполнившая проверку
+	Latency          time.Duration
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Status           string // CheckStatus*
+	Error            string // Текст ошибки для неуспешных проверок
+	CreatedAt        time.Time
+}
+
+// NewCheck создаёт запись о проверке текста пользователя
+func NewCheck(telegramID, chatID int64, originalText string) *Check {
+	return &Check{
+		TelegramID:   telegramID,
+		ChatID:       chatID,
+		OriginalText: originalText,
+		CreatedAt:    time.Now(),
+	}
+}
@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+// Формат вывода исправленного текста
+const (
+	OutputFormatCode  = "code"  // в блоке кода для копирования одним нажатием
+	OutputFormatPlain = "plain" // обычным текстом
+)
+
+// Строгость проверки
+const (
+	StrictnessLenient = "lenient" // только явные ошибки
+	StrictnessNormal  = "normal"  // орфография, пунктуация, грамматика
+	StrictnessStrict  = "strict"  // дополнительно стиль и типографика
+)
+
+// Языки проверяемого текста
+const (
+	LanguageRussian   = "ru"
+	LanguageEnglish   = "en"
+	LanguageUkrainian = "uk"
+)
+
+type UserSettings struct {
+	TelegramID       int64  // Telegram User ID владельца настроек
+	OutputFormat     string // OutputFormat*
+	ShowExplanations bool   // Показывать список исправлений
+	DiffView         bool   // Показывать подсветку изменений
+	Strictness       string // Strictness*
+	Language         string // Language*
+	UpdatedAt        time.Time
+}
+
+// DefaultUserSettings возвращает настройки для пользователя, который их ещё не менял
+func DefaultUserSettings(telegramID int64, diffView bool) *UserSettings {
+	return &UserSettings{
+		TelegramID:       telegramID,
+		OutputFormat:     OutputFormatCode,
+		ShowExplanations: true,
+		DiffView:         diffView,
+		Strictness:       StrictnessNormal,
+		Language:         LanguageRussian,
+		UpdatedAt:        time.Now(),
+	}
+}
+
+// ChatSettings - настройки группового чата; меняют только администраторы чата
+type ChatSettings struct {
+	ChatID    int64 // Telegram Chat ID группы
+	AutoCheck bool  // Проверять все сообщения участников, а не только по упоминанию или /check
+	UpdatedAt time.Time
+}
+
+// DefaultChatSettings возвращает настройки для чата, в котором их ещё не меняли
+func DefaultChatSettings(chatID int64) *ChatSettings {
+	return &ChatSettings{
+		ChatID:    chatID,
+		UpdatedAt: time.Now(),
+	}
+}
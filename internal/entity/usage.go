@@ -0,0 +1,9 @@
+package entity
+
+// DailyUsage - расход дневной квоты пользователя
+type DailyUsage struct {
+	TelegramID int64
+	Day        string // дата в UTC в формате 2006-01-02
+	Requests   int    // число проверок за день
+	Characters int    // суммарная длина проверенных текстов в символах
+}
@@ -0,0 +1,30 @@
+package health
+
+import (
+	"context"
+	"errors"
+
+	"spell_bot/internal/checker"
+)
+
+var _ checker.Checker = (*TrackedChecker)(nil)
+
+// TrackedChecker записывает исход каждой проверки в Window.
+// Проверки, отменённые вызывающим (например, при остановке), не учитываются.
+type TrackedChecker struct {
+	inner  checker.Checker
+	window *Window
+}
+
+// Track оборачивает checker учётом доли успешных запросов
+func Track(inner checker.Checker, window *Window) *TrackedChecker {
+	return &TrackedChecker{inner: inner, window: window}
+}
+
+func (c *TrackedChecker) Check(ctx context.Context, text string, opts checker.Options) (*checker.CheckResponse, error) {
+	resp, err := c.inner.Check(ctx, text, opts)
+	if err == nil || !errors.Is(ctx.Err(), context.Canceled) {
+		c.window.Record(err == nil)
+	}
+	return resp, err
+}
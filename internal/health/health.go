@@ -0,0 +1,65 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Result - результат одной проверки состояния
+type Result struct {
+	OK      bool           `json:"ok"`
+	Error   string         `json:"error,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Probe проверяет одну зависимость
+type Probe func(ctx context.Context) Result
+
+// Report - ответ /healthz и /readyz
+type Report struct {
+	OK     bool              `json:"ok"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Handler выполняет probes параллельно с общим таймаутом и отвечает
+// 200, если все проверки успешны, иначе 503
+func Handler(timeout time.Duration, probes map[string]Probe) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		report := Report{OK: true, Checks: make(map[string]Result, len(probes))}
+
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+		for name, probe := range probes {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result := probe(ctx)
+
+				mu.Lock()
+				defer mu.Unlock()
+				report.Checks[name] = result
+				if !result.OK {
+					report.OK = false
+				}
+			}()
+		}
+		wg.Wait()
+
+		status := http.StatusOK
+		if !report.OK {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
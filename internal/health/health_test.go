@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func okProbe(context.Context) Result {
+	return Result{OK: true, Details: map[string]any{"mode": "polling"}}
+}
+
+func failingProbe(context.Context) Result {
+	return Result{Error: "no successful getUpdates for 5m0s"}
+}
+
+// serve выполняет запрос к Handler и разбирает JSON-отчёт
+func serve(t *testing.T, h http.Handler) (int, Report) {
+	t.Helper()
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", ct)
+	}
+	var report Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	return resp.StatusCode, report
+}
+
+func TestHandlerAllProbesOK(t *testing.T) {
+	status, report := serve(t, Handler(time.Second, map[string]Probe{
+		"bot":     okProbe,
+		"storage": okProbe,
+	}))
+
+	if status != http.StatusOK || !report.OK {
+		t.Fatalf("status %d, report %+v; want 200 and ok", status, report)
+	}
+	if len(report.Checks) != 2 || report.Checks["bot"].Details["mode"] != "polling" {
+		t.Fatalf("Checks = %+v, want both probes with details", report.Checks)
+	}
+}
+
+func TestHandlerFailingProbe(t *testing.T) {
+	status, report := serve(t, Handler(time.Second, map[string]Probe{
+		"bot":     failingProbe,
+		"storage": okProbe,
+	}))
+
+	if status != http.StatusServiceUnavailable || report.OK {
+		t.Fatalf("status %d, report %+v; want 503 and not ok", status, report)
+	}
+	if bot := report.Checks["bot"]; bot.OK || bot.Error != "no successful getUpdates for 5m0s" {
+		t.Fatalf("bot check = %+v, want failure with error", bot)
+	}
+	if !report.Checks["storage"].OK {
+		t.Fatalf("storage check = %+v, want ok", report.Checks["storage"])
+	}
+}
+
+func TestHandlerProbeTimeout(t *testing.T) {
+	slow := func(ctx context.Context) Result {
+		<-ctx.Done()
+		return Result{Error: ctx.Err().Error()}
+	}
+
+	start := time.Now()
+	status, report := serve(t, Handler(50*time.Millisecond, map[string]Probe{"storage": slow}))
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Handler took %v, want probe timeout to apply", elapsed)
+	}
+	if status != http.StatusServiceUnavailable || report.Checks["storage"].Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("status %d, report %+v; want 503 with deadline error", status, report)
+	}
+}
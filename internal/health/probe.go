@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Loop - цикл получения обновлений бота
+type Loop interface {
+	Running() bool
+	LastPoll() time.Time
+}
+
+// LoopProbe проверяет, что цикл получения обновлений жив. При staleAfter > 0
+// дополнительно требуется успешный опрос не позже staleAfter назад; до первого
+// опроса время отсчитывается от startedAt.
+func LoopProbe(loop Loop, mode string, startedAt time.Time, staleAfter time.Duration) Probe {
+	return func(context.Context) Result {
+		lastPoll := loop.LastPoll()
+		result := Result{
+			OK:      loop.Running(),
+			Details: map[string]any{"mode": mode},
+		}
+		if !lastPoll.IsZero() {
+			result.Details["last_update_poll"] = lastPoll.UTC().Format(time.RFC3339)
+		}
+
+		if !result.OK {
+			result.Error = "bot loop is not running"
+			return result
+		}
+
+		if staleAfter > 0 {
+			since := lastPoll
+			if since.IsZero() {
+				since = startedAt
+			}
+			if time.Since(since) > staleAfter {
+				result.OK = false
+				result.Error = fmt.Sprintf("no successful getUpdates for %s", time.Since(since).Round(time.Second))
+			}
+		}
+
+		return result
+	}
+}
+
+// SuccessRateProbe сообщает о сбое, если в окне набралось не меньше minSamples
+// операций и доля успешных среди них ниже minRate
+func SuccessRateProbe(window *Window, minRate float64, minSamples int) Probe {
+	return func(context.Context) Result {
+		rate, samples := window.SuccessRate()
+		result := Result{
+			OK:      true,
+			Details: map[string]any{"success_rate": rate, "samples": samples},
+		}
+		if samples >= minSamples && rate < minRate {
+			result.OK = false
+			result.Error = "too many recent checker failures"
+		}
+		return result
+	}
+}
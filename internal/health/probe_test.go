@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeLoop - цикл получения обновлений с заданным состоянием
+type fakeLoop struct {
+	running  bool
+	lastPoll time.Time
+}
+
+func (l fakeLoop) Running() bool       { return l.running }
+func (l fakeLoop) LastPoll() time.Time { return l.lastPoll }
+
+func TestLoopProbe(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		loop       fakeLoop
+		startedAt  time.Time
+		staleAfter time.Duration
+		wantOK     bool
+	}{
+		{"not running", fakeLoop{lastPoll: now}, now, time.Minute, false},
+		{"recent poll", fakeLoop{running: true, lastPoll: now.Add(-30 * time.Second)}, now.Add(-time.Hour), time.Minute, true},
+		{"stale poll", fakeLoop{running: true, lastPoll: now.Add(-2 * time.Minute)}, now.Add(-time.Hour), time.Minute, false},
+		{"no poll yet after start", fakeLoop{running: true}, now.Add(-30 * time.Second), time.Minute, true},
+		{"no poll long after start", fakeLoop{running: true}, now.Add(-2 * time.Minute), time.Minute, false},
+		{"staleness disabled", fakeLoop{running: true, lastPoll: now.Add(-time.Hour)}, now.Add(-time.Hour), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := LoopProbe(tt.loop, "polling", tt.startedAt, tt.staleAfter)(context.Background())
+			if result.OK != tt.wantOK {
+				t.Fatalf("OK = %v, want %v (error %q)", result.OK, tt.wantOK, result.Error)
+			}
+			if !result.OK && result.Error == "" {
+				t.Fatal("failed probe without error")
+			}
+			if result.Details["mode"] != "polling" {
+				t.Fatalf("Details = %v, want mode", result.Details)
+			}
+			if _, ok := result.Details["last_update_poll"]; ok == tt.loop.lastPoll.IsZero() {
+				t.Fatalf("Details = %v, last_update_poll must be set only after a poll", result.Details)
+			}
+		})
+	}
+}
+
+func TestSuccessRateProbe(t *testing.T) {
+	const minRate, minSamples = 0.8, 5
+
+	tests := []struct {
+		name      string
+		succeeded int
+		failed    int
+		wantOK    bool
+	}{
+		{"no samples", 0, 0, true},
+		{"too few samples to judge", 0, 4, true},
+		{"exactly at threshold", 8, 2, true},
+		{"just below threshold", 7, 3, false},
+		{"all failed", 0, 10, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWindow(10)
+			for i := 0; i < tt.succeeded; i++ {
+				w.Record(true)
+			}
+			for i := 0; i < tt.failed; i++ {
+				w.Record(false)
+			}
+
+			result := SuccessRateProbe(w, minRate, minSamples)(context.Background())
+			if result.OK != tt.wantOK {
+				t.Fatalf("OK = %v, want %v (details %v)", result.OK, tt.wantOK, result.Details)
+			}
+			if result.Details["samples"] != tt.succeeded+tt.failed {
+				t.Fatalf("Details = %v, want %d samples", result.Details, tt.succeeded+tt.failed)
+			}
+		})
+	}
+}
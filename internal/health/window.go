@@ -0,0 +1,45 @@
+package health
+
+import "sync"
+
+// Window хранит исходы последних size операций для расчёта доли успешных
+type Window struct {
+	mu       sync.Mutex
+	outcomes []bool
+	next     int
+	filled   int
+}
+
+// NewWindow создаёт окно на size последних операций
+func NewWindow(size int) *Window {
+	return &Window{outcomes: make([]bool, max(size, 1))}
+}
+
+// Record добавляет исход операции, вытесняя самый старый
+func (w *Window) Record(ok bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.outcomes[w.next] = ok
+	w.next = (w.next + 1) % len(w.outcomes)
+	w.filled = min(w.filled+1, len(w.outcomes))
+}
+
+// SuccessRate возвращает долю успешных операций и число операций в окне.
+// Пустое окно считается полностью успешным.
+func (w *Window) SuccessRate() (rate float64, samples int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.filled == 0 {
+		return 1, 0
+	}
+
+	succeeded := 0
+	for i := 0; i < w.filled; i++ {
+		if w.outcomes[i] {
+			succeeded++
+		}
+	}
+	return float64(succeeded) / float64(w.filled), w.filled
+}
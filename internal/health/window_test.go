@@ -0,0 +1,34 @@
+package health
+
+import "testing"
+
+func TestWindowSuccessRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		size        int
+		outcomes    []bool
+		wantRate    float64
+		wantSamples int
+	}{
+		{"empty window is healthy", 4, nil, 1, 0},
+		{"partially filled", 4, []bool{true, false}, 0.5, 2},
+		{"full", 4, []bool{true, true, true, false}, 0.75, 4},
+		{"oldest outcomes are evicted", 4, []bool{false, false, true, true, true, true}, 1, 4},
+		{"eviction wraps around", 3, []bool{true, true, true, false, false}, 1.0 / 3, 3},
+		{"zero size keeps last outcome", 0, []bool{true, false}, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWindow(tt.size)
+			for _, ok := range tt.outcomes {
+				w.Record(ok)
+			}
+
+			rate, samples := w.SuccessRate()
+			if rate != tt.wantRate || samples != tt.wantSamples {
+				t.Fatalf("SuccessRate() = %v, %d; want %v, %d", rate, samples, tt.wantRate, tt.wantSamples)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package deepseek
+
+import "spell_bot/internal/llm/openai"
+
+const (
+	DefaultBaseURL = "https://api.deepseek.com/v1"
+	DefaultModel   = "deepseek-chat"
+)
+
+// NewClient создаёт OpenAI-совместимый клиент, настроенный на DeepSeek API.
+// Опции opts применяются после пресета и могут переопределить URL и модель.
+func NewClient(apiKey string, opts ...openai.Option) *openai.Client {
+	preset := []openai.Option{
+		openai.WithBaseURL(DefaultBaseURL),
+		openai.WithModel(DefaultModel),
+	}
+	return openai.NewClient(apiKey, append(preset, opts...)...)
+}
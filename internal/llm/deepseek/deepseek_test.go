@@ -0,0 +1,16 @@
+package deepseek
+
+import (
+	"testing"
+
+	"spell_bot/internal/llm/openai"
+)
+
+func TestNewClientPreset(t *testing.T) {
+	if got := NewClient("key").Model(); got != DefaultModel {
+		t.Errorf("Model() = %q, want %q", got, DefaultModel)
+	}
+	if got := NewClient("key", openai.WithModel("deepseek-reasoner")).Model(); got != "deepseek-reasoner" {
+		t.Errorf("Model() = %q, want deepseek-reasoner", got)
+	}
+}
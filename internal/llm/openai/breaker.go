@@ -0,0 +1,149 @@
+package openai
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"spell_bot/internal/checker"
+)
+
+// ErrCircuitOpen возвращается, пока circuit breaker не пропускает запросы к API
+var ErrCircuitOpen = fmt.Errorf("circuit breaker is open: %w", checker.ErrUnavailable)
+
+type BreakerState int
+
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker размыкается после threshold последовательных отказов бэкенда,
+// отклоняет запросы в течение cooldown, затем пропускает пробные запросы
+// (half-open): успешная проба замыкает цепь, неудачная снова размыкает.
+type CircuitBreaker struct {
+	mu sync.Mutex
+
+	threshold   int
+	cooldown    time.Duration
+	halfOpenMax int
+
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probes   int
+
+	now func() time.Time
+}
+
+// NewCircuitBreaker создаёт breaker; threshold <= 0 отключает его
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold:   threshold,
+		cooldown:    cooldown,
+		halfOpenMax: 1,
+		now:         time.Now,
+	}
+}
+
+// Allow проверяет, можно ли выполнить запрос. Каждый разрешённый вызов
+// должен завершаться Success, Failure или Release.
+func (b *CircuitBreaker) Allow() error {
+	if b == nil || b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = StateHalfOpen
+		b.probes = 0
+		fallthrough
+	case StateHalfOpen:
+		if b.probes >= b.halfOpenMax {
+			return ErrCircuitOpen
+		}
+		b.probes++
+	}
+
+	return nil
+}
+
+// Success фиксирует успешный ответ бэкенда и замыкает цепь
+func (b *CircuitBreaker) Success() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.failures = 0
+	b.probes = 0
+}
+
+// Failure фиксирует отказ бэкенда
+func (b *CircuitBreaker) Failure() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.state = StateOpen
+		b.openedAt = b.now()
+		b.probes = 0
+	}
+}
+
+// Release освобождает разрешение без оценки бэкенда (например, запрос отменён вызывающим)
+func (b *CircuitBreaker) Release() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// State возвращает текущее состояние breaker
+func (b *CircuitBreaker) State() BreakerState {
+	if b == nil {
+		return StateClosed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return StateHalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,163 @@
+package openai
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock - управляемые часы для breaker
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*CircuitBreaker, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	b := NewCircuitBreaker(threshold, cooldown)
+	b.now = clock.now
+	return b, clock
+}
+
+// openBreaker доводит breaker до размыкания
+func openBreaker(t *testing.T, b *CircuitBreaker) {
+	t.Helper()
+	for i := 0; i < b.threshold; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow before threshold: %v", err)
+		}
+		b.Failure()
+	}
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("state = %v, want open", got)
+	}
+}
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	b, _ := newTestBreaker(3, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		b.Allow()
+		b.Failure()
+	}
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("state = %v, want closed below threshold", got)
+	}
+
+	// Успех сбрасывает счётчик последовательных отказов
+	b.Allow()
+	b.Success()
+	for i := 0; i < 2; i++ {
+		b.Allow()
+		b.Failure()
+	}
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("state = %v, want closed after success reset", got)
+	}
+
+	b.Allow()
+	b.Failure()
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("state = %v, want open", got)
+	}
+}
+
+func TestBreakerRejectsDuringCooldown(t *testing.T) {
+	b, clock := newTestBreaker(1, time.Minute)
+	openBreaker(t, b)
+
+	clock.advance(59 * time.Second)
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow during cooldown = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestBreakerHalfOpenAllowsSingleProbe(t *testing.T) {
+	b, clock := newTestBreaker(1, time.Minute)
+	openBreaker(t, b)
+
+	clock.advance(time.Minute)
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("state = %v, want half-open after cooldown", got)
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe Allow: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second Allow during probe = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestBreakerProbeSuccessCloses(t *testing.T) {
+	b, clock := newTestBreaker(1, time.Minute)
+	openBreaker(t, b)
+
+	clock.advance(time.Minute)
+	b.Allow()
+	b.Success()
+
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("state = %v, want closed", got)
+	}
+	for i := 0; i < 3; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow after close: %v", err)
+		}
+		b.Success()
+	}
+}
+
+func TestBreakerProbeFailureReopens(t *testing.T) {
+	b, clock := newTestBreaker(3, time.Minute)
+	openBreaker(t, b)
+
+	clock.advance(time.Minute)
+	b.Allow()
+	b.Failure()
+
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("state = %v, want open after failed probe", got)
+	}
+	// Cooldown отсчитывается заново с момента неудачной пробы
+	clock.advance(59 * time.Second)
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow = %v, want ErrCircuitOpen", err)
+	}
+	clock.advance(time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe Allow after second cooldown: %v", err)
+	}
+}
+
+func TestBreakerReleaseFreesProbe(t *testing.T) {
+	b, clock := newTestBreaker(1, time.Minute)
+	openBreaker(t, b)
+
+	clock.advance(time.Minute)
+	b.Allow()
+	b.Release()
+
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("state = %v, want half-open after release", got)
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow after release: %v", err)
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	var nilBreaker *CircuitBreaker
+	disabled := NewCircuitBreaker(0, time.Minute)
+
+	for _, b := range []*CircuitBreaker{nilBreaker, disabled} {
+		for i := 0; i < 10; i++ {
+			if err := b.Allow(); err != nil {
+				t.Fatalf("Allow: %v", err)
+			}
+			b.Failure()
+		}
+		if got := b.State(); got != StateClosed {
+			t.Fatalf("state = %v, want closed", got)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"spell_bot/internal/checker"
+	"spell_bot/internal/metrics"
+)
+
+var _ checker.Checker = (*Client)(nil)
+
+type Client struct {
+	apiKey     string
+	httpClient *http.Client
+	baseURL    string
+	model      string
+	headers    map[string]string
+	retry      RetryPolicy
+	breaker    *CircuitBreaker
+	metrics    *metrics.Metrics
+}
+
+type ChatCompletionRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+}
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ChatCompletionResponse struct {
+	Model   string        `json:"model"`
+	Choices []Choice      `json:"choices"`
+	Usage   checker.Usage `json:"usage"`
+}
+
+type Choice struct {
+	Message struct {
+		Content string `json:"content"`
+	} `json:"message"`
+}
+
+type ErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		apiKey: apiKey,
+		httpClient: &http.Client{
+			Timeout: DefaultTimeout,
+		},
+		baseURL: DefaultBaseURL,
+		model:   DefaultModel,
+		headers: make(map[string]string),
+		retry:   DefaultRetryPolicy,
+		breaker: NewCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Model возвращает имя модели, используемой клиентом
+func (c *Client) Model() string {
+	return c.model
+}
+
+// Check проверяет орфографию и пунктуацию текста через OpenAI-совместимый API
+func (c *Client) Check(ctx context.Context, text string, opts checker.Options) (*checker.CheckResponse, error) {
+	if text == "" {
+		return nil, fmt.Errorf("text cannot be empty")
+	}
+
+	prompt := buildPrompt(text, opts)
+
+	requestBody := ChatCompletionRequest{
+		Model: c.model,
+		Messages: []Message{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	if err := c.breaker.Allow(); err != nil {
+		c.metrics.LLMError(c.model, "circuit_open")
+		return nil, err
+	}
+
+	body, err := c.doWithRetry(ctx, jsonData)
+	c.recordOutcome(ctx, err)
+	if err != nil {
+		return nil, err
+	}
+
+	var chatResp ChatCompletionResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		c.metrics.LLMError(c.model, "invalid_response")
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	c.metrics.TokensUsed(c.model, chatResp.Usage.PromptTokens, chatResp.Usage.CompletionTokens)
+
+	if len(chatResp.Choices) == 0 {
+		c.metrics.LLMError(c.model, "invalid_response")
+		return nil, fmt.Errorf("no response choices received")
+	}
+
+	responseContent := chatResp.Choices[0].Message.Content
+
+	// Extract JSON from markdown code block if present
+	jsonContent := extractJSONFromResponse(responseContent)
+
+	// Parse the JSON response from the AI
+	var checkResp checker.CheckResponse
+	if err := json.Unmarshal([]byte(jsonContent), &checkResp); err != nil {
+		c.metrics.LLMError(c.model, "invalid_response")
+		return nil, fmt.Errorf("failed to parse AI response as JSON: %w, content: %s", err, jsonContent)
+	}
+	checkResp.Edits = checker.ValidateEdits(text, checkResp.Edits)
+	checkResp.Usage = chatResp.Usage
+	checkResp.Model = chatResp.Model
+	if checkResp.Model == "" {
+		checkResp.Model = c.model
+	}
+
+	return &checkResp, nil
+}
+
+// doRequest выполняет одну попытку запроса к /chat/completions
+func (c *Client) doRequest(ctx context.Context, payload []byte) (body []byte, err error) {
+	start := time.Now()
+	defer func() {
+		c.metrics.LLMRequest(c.model, time.Since(start), errorClass(err))
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	// Self-hosted эндпоинты (Ollama, vLLM) часто работают без ключа
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &transportError{err: fmt.Errorf("failed to send request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &transportError{err: fmt.Errorf("failed to read response body: %w", err)}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+
+		var errorResp ErrorResponse
+		if err := json.Unmarshal(body, &errorResp); err == nil {
+			apiErr.Type = errorResp.Error.Type
+			apiErr.Message = errorResp.Error.Message
+		} else {
+			apiErr.Message = string(body)
+		}
+		return nil, apiErr
+	}
+
+	return body, nil
+}
+
+// BreakerState возвращает состояние circuit breaker клиента
+func (c *Client) BreakerState() BreakerState {
+	return c.breaker.State()
+}
+
+// recordOutcome сообщает circuit breaker результат обращения к бэкенду
+func (c *Client) recordOutcome(ctx context.Context, err error) {
+	switch {
+	case err == nil:
+		c.breaker.Success()
+	case ctx.Err() != nil:
+		c.breaker.Release()
+	case isRetryable(ctx, err):
+		// Сеть, 429 и 5xx - признаки недоступности бэкенда
+		c.breaker.Failure()
+	default:
+		// Бэкенд ответил, хоть и ошибкой запроса
+		c.breaker.Success()
+	}
+}
+
+func (c *Client) SetBaseURL(url string) {
+	c.baseURL = url
+}
+
+// extractJSONFromResponse extracts JSON content from markdown code blocks
+func extractJSONFromResponse(content string) string {
+	// Remove markdown code block markers
+	re := regexp.MustCompile(`(?s)` + "```" + `(?:json)?\s*([\s\S]*?)\s*` + "```")
+	matches := re.FindStringSubmatch(content)
+	if len(matches) >= 2 {
+		return strings.TrimSpace(matches[1])
+	}
+
+	// If no code block found, try to find JSON object boundaries
+	content = strings.TrimSpace(content)
+	if strings.HasPrefix(content, "{") && strings.HasSuffix(content, "}") {
+		return content
+	}
+
+	// Look for JSON object within the text
+	startIdx := strings.Index(content, "{")
+	endIdx := strings.LastIndex(content, "}")
+	if startIdx >= 0 && endIdx > startIdx {
+		return content[startIdx : endIdx+1]
+	}
+
+	return content
+}
@@ -0,0 +1,127 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"spell_bot/internal/checker"
+)
+
+const checkJSON = `{"corrected_text": "Привет, мир!", "has_changes": true, "explanation": "запятая", "edits": []}`
+
+// newStubServer запускает OpenAI-совместимый сервер, отвечающий content
+// и передающий каждый запрос в inspect
+func newStubServer(t *testing.T, content string, inspect func(r *http.Request, body ChatCompletionRequest)) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if inspect != nil {
+			inspect(r, body)
+		}
+
+		resp := map[string]any{
+			"model":   body.Model,
+			"choices": []map[string]any{{"message": map[string]string{"content": content}}},
+			"usage":   map[string]int{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestClientSendsModelBaseURLAndHeaders(t *testing.T) {
+	var got *http.Request
+	var gotBody ChatCompletionRequest
+	srv := newStubServer(t, checkJSON, func(r *http.Request, body ChatCompletionRequest) {
+		got, gotBody = r, body
+	})
+
+	client := NewClient("secret",
+		WithBaseURL(srv.URL+"/v1"),
+		WithModel("test-model"),
+		WithHeaders(map[string]string{"X-Project": "spell"}),
+	)
+
+	resp, err := client.Check(context.Background(), "Привет мир!", checker.Options{})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	if got.URL.Path != "/v1/chat/completions" {
+		t.Errorf("path = %q, want /v1/chat/completions", got.URL.Path)
+	}
+	if gotBody.Model != "test-model" {
+		t.Errorf("model = %q, want test-model", gotBody.Model)
+	}
+	if h := got.Header.Get("Authorization"); h != "Bearer secret" {
+		t.Errorf("Authorization = %q, want Bearer secret", h)
+	}
+	if h := got.Header.Get("X-Project"); h != "spell" {
+		t.Errorf("X-Project = %q, want spell", h)
+	}
+	if resp.Model != "test-model" || resp.Usage.TotalTokens != 15 {
+		t.Errorf("model/usage = %q/%d, want test-model/15", resp.Model, resp.Usage.TotalTokens)
+	}
+}
+
+func TestClientWithoutAPIKey(t *testing.T) {
+	var auth []string
+	srv := newStubServer(t, checkJSON, func(r *http.Request, _ ChatCompletionRequest) {
+		auth = r.Header.Values("Authorization")
+	})
+
+	client := NewClient("", WithBaseURL(srv.URL), WithModel("local"))
+	if _, err := client.Check(context.Background(), "Привет мир!", checker.Options{}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	if len(auth) != 0 {
+		t.Errorf("Authorization = %q, want no header", auth)
+	}
+}
+
+func TestClientParsesResponseJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"plain", checkJSON},
+		{"fenced json", "```json\n" + checkJSON + "\n```"},
+		{"fenced", "```\n" + checkJSON + "\n```"},
+		{"surrounded by text", "Вот результат:\n" + checkJSON + "\nГотово."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newStubServer(t, tt.content, nil)
+			client := NewClient("key", WithBaseURL(srv.URL))
+
+			resp, err := client.Check(context.Background(), "Привет мир!", checker.Options{})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if !resp.HasChanges || resp.CorrectedText != "Привет, мир!" {
+				t.Errorf("response = %+v, want corrected text with changes", resp)
+			}
+		})
+	}
+}
+
+func TestClientInvalidResponse(t *testing.T) {
+	srv := newStubServer(t, "не JSON", nil)
+	client := NewClient("key", WithBaseURL(srv.URL))
+
+	if _, err := client.Check(context.Background(), "Привет мир!", checker.Options{}); err == nil {
+		t.Fatal("Check: want error for non-JSON content")
+	}
+}
@@ -0,0 +1,78 @@
+package openai
+
+import (
+	"net/http"
+	"time"
+
+	"spell_bot/internal/metrics"
+)
+
+const (
+	DefaultBaseURL = "https://api.openai.com/v1"
+	DefaultModel   = "gpt-4o-mini"
+	DefaultTimeout = 30 * time.Second
+
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// Option настраивает Client. Опции позволяют направить клиент на любой
+// OpenAI-совместимый эндпоинт /chat/completions (vLLM, Ollama, другие вендоры).
+type Option func(*Client)
+
+// WithBaseURL задаёт базовый URL API (без завершающего /chat/completions)
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		if url != "" {
+			c.baseURL = url
+		}
+	}
+}
+
+// WithModel задаёт имя модели, передаваемое в запросе
+func WithModel(model string) Option {
+	return func(c *Client) {
+		if model != "" {
+			c.model = model
+		}
+	}
+}
+
+// WithHeaders добавляет дополнительные заголовки к каждому запросу
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		for k, v := range headers {
+			c.headers[k] = v
+		}
+	}
+}
+
+// WithHTTPClient заменяет HTTP клиент, например для тестового сервера
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithRetryPolicy задаёт политику повторных попыток
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithCircuitBreaker задаёт circuit breaker; nil отключает его
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(c *Client) {
+		c.breaker = breaker
+	}
+}
+
+// WithMetrics включает метрики длительности запросов, ошибок и расхода токенов
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
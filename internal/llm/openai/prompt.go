@@ -0,0 +1,77 @@
+package openai
+
+import (
+	"fmt"
+
+	"spell_bot/internal/checker"
+	"spell_bot/internal/entity"
+)
+
+// PromptVersion входит в ключ кеша ответов; увеличивайте её при изменении промпта
+const PromptVersion = "2"
+
+// languageNames - название языка текста в родительном падеже для промпта
+var languageNames = map[string]string{
+	entity.LanguageRussian:   "русского",
+	entity.LanguageEnglish:   "английского",
+	entity.LanguageUkrainian: "украинского",
+}
+
+// strictnessRules - правила промпта для каждого уровня строгости
+var strictnessRules = map[string]string{
+	entity.StrictnessLenient: `- Исправь только явные орфографические и пунктуационные ошибки
+- Не меняй формулировки, порядок слов и спорные по правилам места
+`,
+	entity.StrictnessNormal: `- Исправь ВСЕ орфографические, пунктуационные и грамматические ошибки
+`,
+	entity.StrictnessStrict: `- Исправь ВСЕ орфографические, пунктуационные и грамматические ошибки
+- Дополнительно исправь стилистические погрешности и типографику (кавычки, тире, пробелы)
+`,
+}
+
+// placeholderRule добавляется, если в тексте есть заполнители кода и ссылок
+const placeholderRule = `- Фрагменты вида ⟦0⟧, ⟦1⟧ - заполнители кода, ссылок и упоминаний: оставь каждый без изменений и на своём месте
+`
+
+func buildPrompt(text string, opts checker.Options) string {
+	language, ok := languageNames[opts.Language]
+	if !ok {
+		language = languageNames[entity.LanguageRussian]
+	}
+
+	rules, ok := strictnessRules[opts.Strictness]
+	if !ok {
+		rules = strictnessRules[entity.StrictnessNormal]
+	}
+	if checker.HasPlaceholders(text) {
+		rules += placeholderRule
+	}
+
+	return fmt.Sprintf(`Ты - эксперт по орфографии и пунктуации %s языка. Проверь текст на ошибки и исправь их, сохранив исходный смысл и стиль. Верни ТОЛЬКО валидный JSON без дополнительных комментариев.
+
+Формат ответа:
+{
+  "corrected_text": "исправленный текст",
+  "has_changes": true/false,
+  "explanation": "краткое объяснение сделанных исправлений или пустая строка если изменений нет",
+  "edits": [
+    {
+      "start": 0,
+      "end": 6,
+      "original": "исходный фрагмент",
+      "replacement": "исправленный фрагмент",
+      "category": "spelling | punctuation | grammar | style",
+      "rationale": "короткое объяснение правки"
+    }
+  ]
+}
+
+Важно:
+%s- Сохрани исходный смысл, тон и стиль текста
+- Если ошибок нет, верни исходный текст в corrected_text и has_changes: false
+- В explanation кратко опиши что было исправлено
+- В edits перечисли каждое исправление отдельно: start и end - позиции фрагмента original в исходном тексте в символах (end не включается), original - точная копия фрагмента из исходного текста
+- Если ошибок нет, верни пустой массив edits
+
+Текст: "%s"`, language, rules, text)
+}
@@ -0,0 +1,176 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy описывает повторные попытки запроса к API
+type RetryPolicy struct {
+	MaxAttempts int           // общее число попыток, включая первую
+	BaseDelay   time.Duration // задержка перед второй попыткой
+	MaxDelay    time.Duration // верхняя граница задержки между попытками
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+}
+
+// APIError - ошибка, полученная от API в виде ответа с не-200 статусом
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+	RetryAfter time.Duration // значение заголовка Retry-After, 0 если его нет
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("API error (status %d, type %q): %s", e.StatusCode, e.Type, e.Message)
+}
+
+// Retryable сообщает, имеет ли смысл повторить запрос
+func (e *APIError) Retryable() bool {
+	switch e.Type {
+	case "rate_limit_error", "rate_limit_exceeded", "server_error", "overloaded_error", "service_unavailable":
+		return true
+	case "invalid_request_error", "authentication_error", "permission_error", "insufficient_quota":
+		return false
+	}
+
+	return e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode >= http.StatusInternalServerError
+}
+
+// isRetryable классифицирует ошибку попытки запроса
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	var transportErr *transportError
+	return errors.As(err, &transportErr)
+}
+
+// errorClass возвращает класс ошибки попытки запроса для метрик
+func errorClass(err error) string {
+	var apiErr *APIError
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.As(err, &apiErr):
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			return "rate_limited"
+		case apiErr.StatusCode >= http.StatusInternalServerError:
+			return "server_error"
+		default:
+			return "client_error"
+		}
+	default:
+		return "network"
+	}
+}
+
+// transportError оборачивает сетевые ошибки и ошибки чтения ответа
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string { return e.err.Error() }
+func (e *transportError) Unwrap() error { return e.err }
+
+// doWithRetry выполняет запрос с экспоненциальной задержкой и джиттером,
+// учитывая Retry-After и дедлайн контекста вызывающего
+func (c *Client) doWithRetry(ctx context.Context, payload []byte) ([]byte, error) {
+	attempts := max(c.retry.MaxAttempts, 1)
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		body, err := c.doRequest(ctx, payload)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+
+		if attempt == attempts || !isRetryable(ctx, err) {
+			break
+		}
+
+		delay := c.retry.backoff(attempt)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+			delay = apiErr.RetryAfter
+		}
+
+		// Не ждём, если следующая попытка всё равно не уложится в дедлайн
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			break
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("retry aborted: %w (last error: %v)", ctx.Err(), lastErr)
+		case <-timer.C:
+		}
+	}
+
+	return nil, lastErr
+}
+
+// backoff возвращает задержку перед попыткой attempt+1 (full jitter)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+
+	ceiling := p.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || (p.MaxDelay > 0 && ceiling > p.MaxDelay) {
+		ceiling = p.MaxDelay
+	}
+
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// parseRetryAfter разбирает Retry-After в секундах или в формате HTTP-даты
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,173 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"spell_bot/internal/checker"
+)
+
+// fastRetry - политика без заметных задержек для тестов
+var fastRetry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+// newFailingServer отвечает статусом status с телом body на первые failures запросов,
+// затем валидным ответом; возвращает счётчик запросов
+func newFailingServer(t *testing.T, failures int32, status int, header http.Header, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	ok := newStubServer(t, checkJSON, nil)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+			return
+		}
+		ok.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &calls
+}
+
+func TestRetryRecoversFromServerErrors(t *testing.T) {
+	srv, calls := newFailingServer(t, 2, http.StatusServiceUnavailable, nil, "")
+	client := NewClient("key", WithBaseURL(srv.URL), WithRetryPolicy(fastRetry), WithCircuitBreaker(nil))
+
+	if _, err := client.Check(context.Background(), "текст", checker.Options{}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestRetryStopsOnTerminalErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"bad request", http.StatusBadRequest, `{"error": {"type": "invalid_request_error", "message": "bad"}}`},
+		{"unauthorized", http.StatusUnauthorized, `{"error": {"type": "authentication_error", "message": "no key"}}`},
+		{"quota on 429", http.StatusTooManyRequests, `{"error": {"type": "insufficient_quota", "message": "pay"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := newFailingServer(t, 10, tt.status, nil, tt.body)
+			client := NewClient("key", WithBaseURL(srv.URL), WithRetryPolicy(fastRetry), WithCircuitBreaker(nil))
+
+			_, err := client.Check(context.Background(), "текст", checker.Options{})
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Fatalf("err = %v, want APIError with status %d", err, tt.status)
+			}
+			if got := calls.Load(); got != 1 {
+				t.Errorf("requests = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	srv, calls := newFailingServer(t, 10, http.StatusInternalServerError, nil, "")
+	client := NewClient("key", WithBaseURL(srv.URL), WithRetryPolicy(fastRetry), WithCircuitBreaker(nil))
+
+	if _, err := client.Check(context.Background(), "текст", checker.Options{}); err == nil {
+		t.Fatal("Check: want error")
+	}
+	if got := calls.Load(); got != int32(fastRetry.MaxAttempts) {
+		t.Errorf("requests = %d, want %d", got, fastRetry.MaxAttempts)
+	}
+}
+
+func TestRetryAfterBeyondDeadlineStopsRetrying(t *testing.T) {
+	header := http.Header{"Retry-After": []string{"30"}}
+	srv, calls := newFailingServer(t, 10, http.StatusTooManyRequests, header, "")
+	client := NewClient("key", WithBaseURL(srv.URL), WithRetryPolicy(fastRetry), WithCircuitBreaker(nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Check(ctx, "текст", checker.Options{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != 30*time.Second {
+		t.Fatalf("err = %v, want APIError with RetryAfter 30s", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Check took %v, want no wait for Retry-After past the deadline", elapsed)
+	}
+}
+
+func TestRetryAfterDelaysNextAttempt(t *testing.T) {
+	header := http.Header{"Retry-After": []string{"1"}}
+	srv, calls := newFailingServer(t, 1, http.StatusTooManyRequests, header, "")
+	client := NewClient("key", WithBaseURL(srv.URL), WithRetryPolicy(fastRetry), WithCircuitBreaker(nil))
+
+	start := time.Now()
+	if _, err := client.Check(context.Background(), "текст", checker.Options{}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Check took %v, want at least the Retry-After delay", elapsed)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(date); got < 58*time.Minute || got > time.Hour {
+		t.Errorf("parseRetryAfter(%q) = %v, want about 1h", date, got)
+	}
+}
+
+func TestAPIErrorRetryable(t *testing.T) {
+	tests := []struct {
+		err  APIError
+		want bool
+	}{
+		{APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{APIError{StatusCode: http.StatusRequestTimeout}, true},
+		{APIError{StatusCode: http.StatusBadGateway}, true},
+		{APIError{StatusCode: http.StatusBadRequest}, false},
+		{APIError{StatusCode: http.StatusNotFound}, false},
+		{APIError{StatusCode: http.StatusTooManyRequests, Type: "insufficient_quota"}, false},
+		{APIError{StatusCode: http.StatusBadRequest, Type: "overloaded_error"}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Retryable(); got != tt.want {
+			t.Errorf("Retryable(%d, %q) = %v, want %v", tt.err.StatusCode, tt.err.Type, got, tt.want)
+		}
+	}
+}
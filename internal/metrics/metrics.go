@@ -0,0 +1,191 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "spell_bot"
+
+// Metrics - метрики бота в формате Prometheus. Все методы безопасно
+// вызывать на nil, поэтому компоненты работают и без метрик.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	updates       *prometheus.CounterVec
+	checks        *prometheus.CounterVec
+	checkDuration prometheus.Histogram
+	llmDuration   *prometheus.HistogramVec
+	llmErrors     *prometheus.CounterVec
+	tokens        *prometheus.CounterVec
+	storageErrors *prometheus.CounterVec
+	cacheLookups  *prometheus.CounterVec
+}
+
+// New создаёт метрики в собственном реестре вместе со стандартными
+// метриками Go-рантайма и процесса
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		updates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "updates_received_total",
+			Help:      "Telegram updates received, by update type.",
+		}, []string{"type"}),
+		checks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "checks_total",
+			Help:      "Text checks, by outcome.",
+		}, []string{"outcome"}),
+		checkDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "check_duration_seconds",
+			Help:      "End-to-end duration of a text check including retries and chunking.",
+			Buckets:   []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120},
+		}),
+		llmDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "llm_request_duration_seconds",
+			Help:      "Duration of a single request to the LLM backend.",
+			Buckets:   []float64{0.25, 0.5, 1, 2, 5, 10, 20, 30},
+		}, []string{"model"}),
+		llmErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "llm_errors_total",
+			Help:      "Failed requests to the LLM backend, by error class.",
+		}, []string{"model", "class"}),
+		tokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "llm_tokens_total",
+			Help:      "Tokens consumed by the LLM backend.",
+		}, []string{"model", "kind"}),
+		storageErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "storage_errors_total",
+			Help:      "Storage operation errors, by operation.",
+		}, []string{"op"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Check response cache lookups, by tier and result (hit or miss).",
+		}, []string{"tier", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.updates,
+		m.checks,
+		m.checkDuration,
+		m.llmDuration,
+		m.llmErrors,
+		m.tokens,
+		m.storageErrors,
+		m.cacheLookups,
+	)
+
+	return m
+}
+
+// Handler отдаёт метрики для /metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterQueue экспортирует загрузку очереди обновлений; stats
+// вызывается при каждом сборе метрик
+func (m *Metrics) RegisterQueue(stats func() (queued, inFlight int64)) {
+	if m == nil {
+		return
+	}
+
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queue_depth",
+			Help:      "Updates waiting in the worker pool queues.",
+		}, func() float64 {
+			queued, _ := stats()
+			return float64(queued)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "updates_in_flight",
+			Help:      "Updates being processed by workers.",
+		}, func() float64 {
+			_, inFlight := stats()
+			return float64(inFlight)
+		}),
+	)
+}
+
+// UpdateReceived учитывает входящее обновление Telegram
+func (m *Metrics) UpdateReceived(updateType string) {
+	if m == nil {
+		return
+	}
+	m.updates.WithLabelValues(updateType).Inc()
+}
+
+// CheckDone учитывает завершённую проверку; duration 0 не попадает в гистограмму
+func (m *Metrics) CheckDone(outcome string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.checks.WithLabelValues(outcome).Inc()
+	if duration > 0 {
+		m.checkDuration.Observe(duration.Seconds())
+	}
+}
+
+// LLMRequest учитывает одну попытку запроса к LLM; пустой class означает успех
+func (m *Metrics) LLMRequest(model string, duration time.Duration, class string) {
+	if m == nil {
+		return
+	}
+	m.llmDuration.WithLabelValues(model).Observe(duration.Seconds())
+	if class != "" {
+		m.llmErrors.WithLabelValues(model, class).Inc()
+	}
+}
+
+// LLMError учитывает ошибку, возникшую вне HTTP-запроса (например, разбор ответа)
+func (m *Metrics) LLMError(model, class string) {
+	if m == nil {
+		return
+	}
+	m.llmErrors.WithLabelValues(model, class).Inc()
+}
+
+// TokensUsed учитывает потраченные токены
+func (m *Metrics) TokensUsed(model string, prompt, completion int) {
+	if m == nil {
+		return
+	}
+	m.tokens.WithLabelValues(model, "prompt").Add(float64(prompt))
+	m.tokens.WithLabelValues(model, "completion").Add(float64(completion))
+}
+
+// StorageError учитывает ошибку операции хранилища
+func (m *Metrics) StorageError(op string) {
+	if m == nil {
+		return
+	}
+	m.storageErrors.WithLabelValues(op).Inc()
+}
+
+// CacheLookup учитывает обращение к уровню кеша ответов
+func (m *Metrics) CacheLookup(tier string, hit bool) {
+	if m == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.WithLabelValues(tier, result).Inc()
+}
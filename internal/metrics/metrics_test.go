@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"spell_bot/internal/storage/memory"
+)
+
+// scrape возвращает текст метрик, отданный Handler
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != 200 {
+		t.Fatalf("GET /metrics: status %d", rec.Code)
+	}
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatalf("read /metrics: %v", err)
+	}
+	return string(body)
+}
+
+func assertMetric(t *testing.T, body, line string) {
+	t.Helper()
+
+	if !strings.Contains(body, line+"\n") {
+		t.Errorf("metrics do not contain %q", line)
+	}
+}
+
+func TestHandlerExportsMetrics(t *testing.T) {
+	m := New()
+	m.UpdateReceived("message")
+	m.UpdateReceived("message")
+	m.CheckDone("ok", 2*time.Second)
+	m.CheckDone("limited", 0)
+	m.LLMRequest("gpt", time.Second, "")
+	m.LLMRequest("gpt", time.Second, "timeout")
+	m.LLMError("gpt", "parse")
+	m.TokensUsed("gpt", 10, 5)
+	m.CacheLookup("lru", true)
+	m.CacheLookup("sqlite", false)
+	m.RegisterQueue(func() (int64, int64) { return 3, 1 })
+
+	body := scrape(t, m)
+	for _, line := range []string{
+		`spell_bot_updates_received_total{type="message"} 2`,
+		`spell_bot_checks_total{outcome="ok"} 1`,
+		`spell_bot_checks_total{outcome="limited"} 1`,
+		// Отклонённая лимитами проверка без длительности не попадает в гистограмму
+		`spell_bot_check_duration_seconds_count 1`,
+		`spell_bot_llm_request_duration_seconds_count{model="gpt"} 2`,
+		`spell_bot_llm_errors_total{class="timeout",model="gpt"} 1`,
+		`spell_bot_llm_errors_total{class="parse",model="gpt"} 1`,
+		`spell_bot_llm_tokens_total{kind="prompt",model="gpt"} 10`,
+		`spell_bot_llm_tokens_total{kind="completion",model="gpt"} 5`,
+		`spell_bot_cache_lookups_total{result="hit",tier="lru"} 1`,
+		`spell_bot_cache_lookups_total{result="miss",tier="sqlite"} 1`,
+		`spell_bot_queue_depth 3`,
+		`spell_bot_updates_in_flight 1`,
+	} {
+		assertMetric(t, body, line)
+	}
+}
+
+func TestNilMetricsIsNoop(t *testing.T) {
+	var m *Metrics
+	m.UpdateReceived("message")
+	m.CheckDone("ok", time.Second)
+	m.LLMRequest("gpt", time.Second, "timeout")
+	m.LLMError("gpt", "parse")
+	m.TokensUsed("gpt", 1, 1)
+	m.StorageError("ping")
+	m.CacheLookup("lru", true)
+	m.RegisterQueue(func() (int64, int64) { return 0, 0 })
+
+	s := InstrumentStorage(failingStorage{memory.NewStorage()}, nil)
+	if err := s.Ping(context.Background()); !errors.Is(err, errStorage) {
+		t.Fatalf("Ping: err = %v, want %v", err, errStorage)
+	}
+}
+
+// failingStorage - хранилище, у которого не работают Ping и ReserveDailyUsage
+type failingStorage struct {
+	*memory.Storage
+}
+
+var errStorage = errors.New("storage unavailable")
+
+func (failingStorage) Ping(context.Context) error {
+	return errStorage
+}
+
+func (failingStorage) ReserveDailyUsage(context.Context, int64, string, int, int, int, int) (bool, error) {
+	return false, errStorage
+}
+
+func TestInstrumentStorageCountsErrors(t *testing.T) {
+	ctx := context.Background()
+	m := New()
+	s := InstrumentStorage(failingStorage{memory.NewStorage()}, m)
+
+	for i := 0; i < 2; i++ {
+		if err := s.Ping(ctx); !errors.Is(err, errStorage) {
+			t.Fatalf("Ping: err = %v, want %v", err, errStorage)
+		}
+	}
+	if _, err := s.ReserveDailyUsage(ctx, 1, "2024-01-01", 1, 10, 0, 0); !errors.Is(err, errStorage) {
+		t.Fatalf("ReserveDailyUsage: err = %v, want %v", err, errStorage)
+	}
+
+	// Отсутствие записи - штатный ответ, а не сбой хранилища
+	if _, err := s.GetCheck(ctx, 42); err == nil {
+		t.Fatal("GetCheck of a missing check: want error")
+	}
+	if _, err := s.GetDailyUsage(ctx, 1, "2024-01-01"); err != nil {
+		t.Fatalf("GetDailyUsage: %v", err)
+	}
+
+	body := scrape(t, m)
+	assertMetric(t, body, `spell_bot_storage_errors_total{op="ping"} 2`)
+	assertMetric(t, body, `spell_bot_storage_errors_total{op="reserve_daily_usage"} 1`)
+	for _, op := range []string{"get_check", "get_daily_usage"} {
+		if strings.Contains(body, `op="`+op+`"`) {
+			t.Errorf("storage error counted for %s", op)
+		}
+	}
+}
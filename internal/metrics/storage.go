@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+
+	"spell_bot/internal/entity"
+	"spell_bot/internal/storage"
+)
+
+var _ storage.Storage = (*Storage)(nil)
+
+// Storage считает ошибки операций хранилища. storage.ErrNotFound
+// ошибкой не считается.
+type Storage struct {
+	storage.Storage
+	metrics *Metrics
+}
+
+// InstrumentStorage оборачивает хранилище подсчётом ошибок
+func InstrumentStorage(s storage.Storage, m *Metrics) *Storage {
+	return &Storage{Storage: s, metrics: m}
+}
+
+func (s *Storage) SaveUser(ctx context.Context, user *entity.User) error {
+	return s.observe("save_user", s.Storage.SaveUser(ctx, user))
+}
+
+func (s *Storage) SaveCheck(ctx context.Context, check *entity.Check) error {
+	return s.observe("save_check", s.Storage.SaveCheck(ctx, check))
+}
+
+func (s *Storage) GetCheck(ctx context.Context, id int64) (*entity.Check, error) {
+	check, err := s.Storage.GetCheck(ctx, id)
+	return check, s.observe("get_check", err)
+}
+
+func (s *Storage) ListChecks(ctx context.Context, telegramID int64, limit, offset int) ([]*entity.Check, error) {
+	checks, err := s.Storage.ListChecks(ctx, telegramID, limit, offset)
+	return checks, s.observe("list_checks", err)
+}
+
+func (s *Storage) CountChecks(ctx context.Context, telegramID int64) (int, error) {
+	count, err := s.Storage.CountChecks(ctx, telegramID)
+	return count, s.observe("count_checks", err)
+}
+
+func (s *Storage) GetUserSettings(ctx context.Context, telegramID int64) (*entity.UserSettings, error) {
+	settings, err := s.Storage.GetUserSettings(ctx, telegramID)
+	return settings, s.observe("get_user_settings", err)
+}
+
+func (s *Storage) SaveUserSettings(ctx context.Context, settings *entity.UserSettings) error {
+	return s.observe("save_user_settings", s.Storage.SaveUserSettings(ctx, settings))
+}
+
+func (s *Storage) GetChatSettings(ctx context.Context, chatID int64) (*entity.ChatSettings, error) {
+	settings, err := s.Storage.GetChatSettings(ctx, chatID)
+	return settings, s.observe("get_chat_settings", err)
+}
+
+func (s *Storage) SaveChatSettings(ctx context.Context, settings *entity.ChatSettings) error {
+	return s.observe("save_chat_settings", s.Storage.SaveChatSettings(ctx, settings))
+}
+
+func (s *Storage) GetDailyUsage(ctx context.Context, telegramID int64, day string) (*entity.DailyUsage, error) {
+	usage, err := s.Storage.GetDailyUsage(ctx, telegramID, day)
+	return usage, s.observe("get_daily_usage", err)
+}
+
+func (s *Storage) ReserveDailyUsage(ctx context.Context, telegramID int64, day string, requests, characters, maxRequests, maxCharacters int) (bool, error) {
+	reserved, err := s.Storage.ReserveDailyUsage(ctx, telegramID, day, requests, characters, maxRequests, maxCharacters)
+	return reserved, s.observe("reserve_daily_usage", err)
+}
+
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.observe("ping", s.Storage.Ping(ctx))
+}
+
+func (s *Storage) observe(op string, err error) error {
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		s.metrics.StorageError(op)
+	}
+	return err
+}
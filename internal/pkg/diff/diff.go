@@ -0,0 +1,251 @@
+package diff
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Segment - непрерывный участок diff с одной операцией
+type Segment struct {
+	Op   Op
+	Text string
+}
+
+// Words вычисляет diff между a и b на уровне слов: слова, пробельные
+// промежутки и отдельные знаки препинания сравниваются как целые токены,
+// поэтому пропущенная запятая видна отдельно от соседнего слова.
+func Words(a, b string) []Segment {
+	return compute(tokenize(a), tokenize(b))
+}
+
+// Chars вычисляет посимвольный diff между a и b
+func Chars(a, b string) []Segment {
+	return compute(splitRunes(a), splitRunes(b))
+}
+
+// HasChanges сообщает, есть ли в diff вставки или удаления
+func HasChanges(segments []Segment) bool {
+	for _, s := range segments {
+		if s.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderHTML выводит diff в Telegram HTML: удалённое зачёркнуто,
+// вставленное выделено жирным с подчёркиванием
+func RenderHTML(segments []Segment) string {
+	var b strings.Builder
+	for _, s := range segments {
+		text := html.EscapeString(s.Text)
+		switch s.Op {
+		case Delete:
+			b.WriteString("<s>" + text + "</s>")
+		case Insert:
+			b.WriteString("<b><u>" + text + "</u></b>")
+		default:
+			b.WriteString(text)
+		}
+	}
+	return b.String()
+}
+
+func compute(a, b []string) []Segment {
+	// Общие префикс и суффикс не участвуют в поиске, это заметно сокращает работу
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var segments []Segment
+	segments = appendSegment(segments, Equal, a[:prefix]...)
+	for _, s := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		segments = appendSegment(segments, s.Op, s.Text)
+	}
+	segments = appendSegment(segments, Equal, a[len(a)-suffix:]...)
+
+	return segments
+}
+
+// maxEditDistance ограничивает длину скрипта редактирования, которую ищет myers:
+// история фронтов занимает O(D²) памяти, поэтому при большем расхождении
+// участок целиком считается заменённым
+const maxEditDistance = 2000
+
+// myers - алгоритм Майерса O((N+M)D) для кратчайшего скрипта редактирования
+func myers(a, b []string) []Segment {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	maxD := min(n+m, maxEditDistance)
+	offset := maxD + 1
+	v := make([]int, 2*offset+1)
+	// trace[d] хранит фронт перед шагом d только для диагоналей -d..d
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, d)
+			}
+		}
+	}
+
+	return replaceAll(a, b)
+}
+
+func backtrack(a, b []string, trace [][]int, d int) []Segment {
+	var reversed []Segment
+	x, y := len(a), len(b)
+
+	for ; d > 0; d-- {
+		// v(k) - фронт перед шагом d на диагонали k
+		v := func(k int) int { return trace[d][k+d] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v(k-1) < v(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, Segment{Op: Equal, Text: a[x]})
+		}
+		if x == prevX {
+			y--
+			reversed = append(reversed, Segment{Op: Insert, Text: b[y]})
+		} else {
+			x--
+			reversed = append(reversed, Segment{Op: Delete, Text: a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		reversed = append(reversed, Segment{Op: Equal, Text: a[x]})
+	}
+
+	segments := make([]Segment, 0, len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+		segments = appendSegment(segments, reversed[i].Op, reversed[i].Text)
+	}
+	return regroup(segments)
+}
+
+// replaceAll описывает участок как удаление a целиком и вставку b
+func replaceAll(a, b []string) []Segment {
+	segments := appendSegment(nil, Delete, a...)
+	return appendSegment(segments, Insert, b...)
+}
+
+// regroup объединяет чередующиеся удаления и вставки, чтобы замена
+// выглядела как "старое → новое", а не как набор мелких кусочков
+func regroup(segments []Segment) []Segment {
+	var result []Segment
+	for i := 0; i < len(segments); {
+		if segments[i].Op == Equal {
+			result = append(result, segments[i])
+			i++
+			continue
+		}
+		var del, ins strings.Builder
+		for ; i < len(segments) && segments[i].Op != Equal; i++ {
+			if segments[i].Op == Delete {
+				del.WriteString(segments[i].Text)
+			} else {
+				ins.WriteString(segments[i].Text)
+			}
+		}
+		result = appendSegment(result, Delete, del.String())
+		result = appendSegment(result, Insert, ins.String())
+	}
+	return result
+}
+
+func appendSegment(segments []Segment, op Op, texts ...string) []Segment {
+	for _, text := range texts {
+		if text == "" {
+			continue
+		}
+		if n := len(segments); n > 0 && segments[n-1].Op == op {
+			segments[n-1].Text += text
+			continue
+		}
+		segments = append(segments, Segment{Op: op, Text: text})
+	}
+	return segments
+}
+
+// tokenize делит текст на слова, пробельные промежутки и отдельные прочие символы
+func tokenize(s string) []string {
+	var tokens []string
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		end := size
+		switch {
+		case isWordRune(r):
+			end = spanWhile(s, isWordRune)
+		case unicode.IsSpace(r):
+			end = spanWhile(s, unicode.IsSpace)
+		}
+		tokens = append(tokens, s[:end])
+		s = s[end:]
+	}
+	return tokens
+}
+
+func spanWhile(s string, f func(rune) bool) int {
+	for i, r := range s {
+		if !f(r) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '\''
+}
+
+func splitRunes(s string) []string {
+	tokens := make([]string, 0, len(s))
+	for _, r := range s {
+		tokens = append(tokens, string(r))
+	}
+	return tokens
+}
@@ -0,0 +1,142 @@
+package diff
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// sides собирает исходный и итоговый текст из diff
+func sides(segments []Segment) (string, string) {
+	var a, b strings.Builder
+	for _, s := range segments {
+		if s.Op != Insert {
+			a.WriteString(s.Text)
+		}
+		if s.Op != Delete {
+			b.WriteString(s.Text)
+		}
+	}
+	return a.String(), b.String()
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Segment
+	}{
+		{
+			name: "equal",
+			a:    "Привет, мир",
+			b:    "Привет, мир",
+			want: []Segment{{Equal, "Привет, мир"}},
+		},
+		{
+			name: "replaced word",
+			a:    "Я пашол домой",
+			b:    "Я пошёл домой",
+			want: []Segment{{Equal, "Я "}, {Delete, "пашол"}, {Insert, "пошёл"}, {Equal, " домой"}},
+		},
+		{
+			name: "missing comma",
+			a:    "Привет мир",
+			b:    "Привет, мир",
+			want: []Segment{{Equal, "Привет"}, {Insert, ","}, {Equal, " мир"}},
+		},
+		{
+			name: "deleted word",
+			a:    "очень очень хорошо",
+			b:    "очень хорошо",
+			want: []Segment{{Equal, "очень "}, {Delete, "очень "}, {Equal, "хорошо"}},
+		},
+		{
+			name: "empty source",
+			a:    "",
+			b:    "текст",
+			want: []Segment{{Insert, "текст"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Words(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Words(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if a, b := sides(got); a != tt.a || b != tt.b {
+				t.Errorf("diff restores %q, %q", a, b)
+			}
+		})
+	}
+}
+
+func TestWordsRegroupsReplacements(t *testing.T) {
+	got := Words("один два три", "раз два четыре")
+	for i := 1; i < len(got); i++ {
+		if got[i-1].Op == Insert && got[i].Op == Delete {
+			t.Fatalf("insert before delete in %v", got)
+		}
+	}
+	if a, b := sides(got); a != "один два три" || b != "раз два четыре" {
+		t.Errorf("diff restores %q, %q", a, b)
+	}
+}
+
+func TestHasChanges(t *testing.T) {
+	if HasChanges(Words("текст", "текст")) {
+		t.Error("HasChanges for equal texts")
+	}
+	if !HasChanges(Words("текст", "тест")) {
+		t.Error("no HasChanges for different texts")
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	segments := []Segment{
+		{Equal, "a < b "},
+		{Delete, "&"},
+		{Insert, "и"},
+		{Equal, " c"},
+	}
+	want := "a &lt; b <s>&amp;</s><b><u>и</u></b> c"
+	if got := RenderHTML(segments); got != want {
+		t.Errorf("RenderHTML = %q, want %q", got, want)
+	}
+}
+
+func TestWordsLargeInput(t *testing.T) {
+	// Около 52 тысяч рун с правкой в каждом десятом слове
+	words := strings.Fields(strings.Repeat("съешь же ещё этих мягких французских булок да выпей чаю ", 950))
+	a := strings.Join(words, " ")
+	for i := 0; i < len(words); i += 10 {
+		words[i] = "правка"
+	}
+	b := strings.Join(words, " ")
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	got := Words(a, b)
+	runtime.ReadMemStats(&after)
+
+	if gotA, gotB := sides(got); gotA != a || gotB != b {
+		t.Fatal("diff does not restore the texts")
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 100<<20 {
+		t.Errorf("Words allocated %d MB", allocated>>20)
+	}
+}
+
+func TestWordsFallsBackAboveEditLimit(t *testing.T) {
+	a := strings.Repeat("а ", maxEditDistance)
+	b := strings.Repeat("б ", maxEditDistance)
+
+	got := Words(a, b)
+	if gotA, gotB := sides(got); gotA != a || gotB != b {
+		t.Fatal("diff does not restore the texts")
+	}
+	if !HasChanges(got) {
+		t.Error("no changes reported")
+	}
+}
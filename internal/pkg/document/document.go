@@ -0,0 +1,108 @@
+package document
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format - поддерживаемый формат документа
+type Format string
+
+const (
+	FormatText     Format = "txt"
+	FormatMarkdown Format = "md"
+	FormatDOCX     Format = "docx"
+	FormatODT      Format = "odt"
+)
+
+var (
+	ErrUnsupported = errors.New("unsupported document format")
+	ErrInvalid     = errors.New("invalid document")
+)
+
+// maxXMLSize ограничивает распакованный размер XML внутри DOCX/ODT (защита от zip-бомб)
+const maxXMLSize = 20 << 20
+
+// Document - текст, извлечённый из файла, и способ собрать файл того же
+// формата с исправленным текстом
+type Document interface {
+	// Text возвращает текст документа; абзацы разделены пустой строкой
+	Text() string
+	// Apply собирает файл, в котором текст заменён на corrected
+	Apply(corrected string) ([]byte, error)
+}
+
+// FormatOf определяет формат по расширению имени файла
+func FormatOf(fileName string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".txt":
+		return FormatText, true
+	case ".md", ".markdown":
+		return FormatMarkdown, true
+	case ".docx":
+		return FormatDOCX, true
+	case ".odt":
+		return FormatODT, true
+	default:
+		return "", false
+	}
+}
+
+// Parse извлекает текст из содержимого файла
+func Parse(format Format, data []byte) (Document, error) {
+	switch format {
+	case FormatText, FormatMarkdown:
+		return parsePlain(data)
+	case FormatDOCX:
+		return parseOffice(data, docxSpec)
+	case FormatODT:
+		return parseOffice(data, odtSpec)
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// plain - .txt и .md: файл целиком является текстом
+type plain struct {
+	bom   bool
+	crlf  bool
+	text  string
+	trail string
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+func parsePlain(data []byte) (*plain, error) {
+	doc := &plain{bom: bytes.HasPrefix(data, utf8BOM)}
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("%w: text is not valid UTF-8", ErrInvalid)
+	}
+
+	doc.crlf = bytes.Contains(data, []byte("\r\n"))
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	doc.text = strings.TrimRight(text, "\n")
+	doc.trail = text[len(doc.text):]
+	return doc, nil
+}
+
+func (d *plain) Text() string {
+	return d.text
+}
+
+func (d *plain) Apply(corrected string) ([]byte, error) {
+	var b bytes.Buffer
+	if d.bom {
+		b.Write(utf8BOM)
+	}
+	text := strings.TrimRight(corrected, "\n") + d.trail
+	if d.crlf {
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	b.WriteString(text)
+	return b.Bytes(), nil
+}
@@ -0,0 +1,371 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"spell_bot/internal/pkg/diff"
+)
+
+// officeSpec описывает разметку текста в XML-документе внутри zip-архива
+type officeSpec struct {
+	// entry - файл архива с текстом документа
+	entry string
+	// paragraph - элементы-абзацы
+	paragraph []xml.Name
+	// inText сообщает, является ли текст внутри элемента текстом документа
+	inText func(parent xml.Name) bool
+	// spaces - пустые элементы, обозначающие пробел, табуляцию или перенос строки
+	spaces []xml.Name
+}
+
+const (
+	wordNS = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	odtNS  = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+)
+
+var docxSpec = officeSpec{
+	entry:     "word/document.xml",
+	paragraph: []xml.Name{{Space: wordNS, Local: "p"}},
+	inText: func(parent xml.Name) bool {
+		return parent == xml.Name{Space: wordNS, Local: "t"}
+	},
+	spaces: []xml.Name{{Space: wordNS, Local: "tab"}, {Space: wordNS, Local: "br"}},
+}
+
+var odtSpec = officeSpec{
+	entry:     "content.xml",
+	paragraph: []xml.Name{{Space: odtNS, Local: "p"}, {Space: odtNS, Local: "h"}},
+	// В ODT текст лежит прямо в абзаце или во вложенных span/a
+	inText: func(parent xml.Name) bool {
+		return parent.Space == odtNS
+	},
+	spaces: []xml.Name{{Space: odtNS, Local: "s"}, {Space: odtNS, Local: "tab"}, {Space: odtNS, Local: "line-break"}},
+}
+
+// textRange - байтовый диапазон текстового узла в исходном XML
+type textRange struct {
+	start, end int
+}
+
+// piece - фрагмент абзаца по порядку: текст узла node (индекс в ranges)
+// или пробельный элемент (node = -1)
+type piece struct {
+	text string
+	node int
+}
+
+type officeParagraph struct {
+	text   string
+	ranges []textRange
+	pieces []piece
+}
+
+// office - DOCX или ODT. Исправленный текст изменённого абзаца раскладывается
+// по его текстовым узлам посимвольным diff, поэтому оформление фрагментов
+// (run) сохраняется; если разложить не удалось, текст записывается в первый
+// узел, а остальные очищаются. Неизменённые абзацы и вся прочая разметка
+// остаются как есть.
+type office struct {
+	spec       officeSpec
+	archive    *zip.Reader
+	xml        []byte
+	paragraphs []officeParagraph
+	// nonEmpty - индексы абзацев, попавших в текст
+	nonEmpty []int
+}
+
+func parseOffice(data []byte, spec officeSpec) (*office, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	content, err := readEntry(archive, spec.entry)
+	if err != nil {
+		return nil, err
+	}
+
+	paragraphs, err := scanParagraphs(content, spec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	doc := &office{spec: spec, archive: archive, xml: content, paragraphs: paragraphs}
+	for i, p := range paragraphs {
+		if p.text != "" {
+			doc.nonEmpty = append(doc.nonEmpty, i)
+		}
+	}
+	return doc, nil
+}
+
+func isOneOf(name xml.Name, names []xml.Name) bool {
+	for _, n := range names {
+		if name == n {
+			return true
+		}
+	}
+	return false
+}
+
+func readEntry(archive *zip.Reader, name string) ([]byte, error) {
+	for _, f := range archive.File {
+		if f.Name != name {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		defer rc.Close()
+
+		data, err := io.ReadAll(io.LimitReader(rc, maxXMLSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		if len(data) > maxXMLSize {
+			return nil, fmt.Errorf("%w: %s is too large", ErrInvalid, name)
+		}
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s not found", ErrInvalid, name)
+}
+
+// scanParagraphs находит абзацы и байтовые диапазоны их текстовых узлов.
+// Вложенный абзац (например, в надписи или сноске) считается отдельным.
+func scanParagraphs(content []byte, spec officeSpec) ([]officeParagraph, error) {
+	var (
+		paragraphs []officeParagraph
+		open       []int      // стек индексов открытых абзацев
+		elements   []xml.Name // стек открытых элементов
+	)
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		before := int(decoder.InputOffset())
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		after := int(decoder.InputOffset())
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := t.Name
+			elements = append(elements, name)
+			switch {
+			case isOneOf(name, spec.paragraph):
+				paragraphs = append(paragraphs, officeParagraph{})
+				open = append(open, len(paragraphs)-1)
+			case len(open) > 0 && isOneOf(name, spec.spaces):
+				current := &paragraphs[open[len(open)-1]]
+				current.pieces = append(current.pieces, piece{text: " ", node: -1})
+			}
+
+		case xml.EndElement:
+			name := elements[len(elements)-1]
+			elements = elements[:len(elements)-1]
+			if isOneOf(name, spec.paragraph) && len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+
+		case xml.CharData:
+			if len(open) == 0 || len(elements) == 0 || !spec.inText(elements[len(elements)-1]) {
+				continue
+			}
+			current := &paragraphs[open[len(open)-1]]
+			current.pieces = append(current.pieces, piece{text: string(t), node: len(current.ranges)})
+			current.ranges = append(current.ranges, textRange{start: before, end: after})
+		}
+	}
+
+	for i := range paragraphs {
+		paragraphs[i].text, _ = normalizeOwned(paragraphs[i].pieces)
+	}
+	return paragraphs, nil
+}
+
+// normalizeOwned склеивает фрагменты абзаца, схлопывая пробельные символы
+// (как strings.Fields), и возвращает для каждого символа результата узел,
+// из которого он взят; пробел, в который попал пробельный элемент, принадлежит ему (-1).
+func normalizeOwned(pieces []piece) (string, []int) {
+	var (
+		b          strings.Builder
+		owners     []int
+		space      bool
+		spaceOwner int
+	)
+	for _, p := range pieces {
+		for _, r := range p.text {
+			if unicode.IsSpace(r) {
+				switch {
+				case !space:
+					space, spaceOwner = true, p.node
+				case p.node < 0:
+					spaceOwner = -1
+				}
+				continue
+			}
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+				owners = append(owners, spaceOwner)
+			}
+			space = false
+			b.WriteRune(r)
+			owners = append(owners, p.node)
+		}
+	}
+	return b.String(), owners
+}
+
+// distribute раскладывает исправленный текст абзаца по текстовым узлам: каждый
+// сохранившийся символ остаётся в своём узле, вставка попадает в узел соседнего
+// символа. false, если правка удаляет пробельный элемент (табуляцию, перенос строки)
+// или её не к чему привязать.
+func distribute(p officeParagraph, fixed string) ([]string, bool) {
+	original, owners := normalizeOwned(p.pieces)
+
+	texts := make([]strings.Builder, len(p.ranges))
+	pos := 0 // индекс следующего символа исходного текста
+	for _, seg := range diff.Chars(original, fixed) {
+		switch seg.Op {
+		case diff.Equal:
+			for _, r := range seg.Text {
+				if owner := owners[pos]; owner >= 0 {
+					texts[owner].WriteRune(r)
+				}
+				pos++
+			}
+		case diff.Delete:
+			for range utf8.RuneCountInString(seg.Text) {
+				if owners[pos] < 0 {
+					return nil, false
+				}
+				pos++
+			}
+		case diff.Insert:
+			owner := -1
+			if pos > 0 {
+				owner = owners[pos-1]
+			}
+			if owner < 0 && pos < len(owners) {
+				owner = owners[pos]
+			}
+			if owner < 0 {
+				return nil, false
+			}
+			texts[owner].WriteString(seg.Text)
+		}
+	}
+
+	out := make([]string, len(texts))
+	for i := range texts {
+		out[i] = texts[i].String()
+	}
+	return out, true
+}
+
+func (d *office) Text() string {
+	texts := make([]string, len(d.nonEmpty))
+	for i, idx := range d.nonEmpty {
+		texts[i] = d.paragraphs[idx].text
+	}
+	return strings.Join(texts, paragraphSeparator)
+}
+
+func (d *office) Apply(corrected string) ([]byte, error) {
+	originals := make([]string, len(d.nonEmpty))
+	for i, idx := range d.nonEmpty {
+		originals[i] = d.paragraphs[idx].text
+	}
+	fixed := splitCorrected(originals, corrected)
+
+	// Абзацы и их узлы идут по возрастанию смещений, поэтому замены
+	// применяются одним проходом
+	var replacements []replacement
+	for i, idx := range d.nonEmpty {
+		if fixed[i] == originals[i] {
+			continue
+		}
+		paragraph := d.paragraphs[idx]
+		texts, ok := distribute(paragraph, fixed[i])
+		if !ok {
+			texts = make([]string, len(paragraph.ranges))
+			texts[0] = fixed[i]
+		}
+		// Текст узлов до правки: узлы без изменений не переписываются,
+		// чтобы не трогать их пробелы и сущности
+		unchanged, _ := distribute(paragraph, paragraph.text)
+
+		for j, r := range paragraph.ranges {
+			if ok && texts[j] == unchanged[j] {
+				continue
+			}
+			replacements = append(replacements, replacement{textRange: r, text: texts[j]})
+		}
+	}
+	if len(replacements) == 0 {
+		return d.rebuild(d.xml)
+	}
+
+	var content bytes.Buffer
+	last := 0
+	for _, r := range replacements {
+		content.Write(d.xml[last:r.start])
+		if err := xml.EscapeText(&content, []byte(r.text)); err != nil {
+			return nil, err
+		}
+		last = r.end
+	}
+	content.Write(d.xml[last:])
+
+	return d.rebuild(content.Bytes())
+}
+
+type replacement struct {
+	textRange
+	text string
+}
+
+// rebuild собирает архив, заменяя только файл с текстом; остальные файлы
+// копируются без перепаковки в исходном порядке (для ODT mimetype должен быть первым)
+func (d *office) rebuild(content []byte) ([]byte, error) {
+	var out bytes.Buffer
+	w := zip.NewWriter(&out)
+
+	for _, f := range d.archive.File {
+		if f.Name != d.spec.entry {
+			if err := w.Copy(f); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		header := &zip.FileHeader{Name: f.Name, Method: f.Method, Modified: f.Modified}
+		fw, err := w.CreateHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fw.Write(content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,158 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+const (
+	docxHeader = `<?xml version="1.0" encoding="UTF-8"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`
+	docxFooter = `</w:body></w:document>`
+	odtHeader  = `<?xml version="1.0" encoding="UTF-8"?><office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:text>`
+	odtFooter  = `</office:text></office:body></office:document-content>`
+)
+
+// buildZip собирает архив из файлов в заданном порядке (имя, содержимое, имя, ...)
+func buildZip(t *testing.T, files ...string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		f, err := w.Create(files[i])
+		if err != nil {
+			t.Fatalf("zip create: %v", err)
+		}
+		f.Write([]byte(files[i+1]))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func readZipEntry(t *testing.T, data []byte, name string) string {
+	t.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip open: %v", err)
+	}
+	for _, f := range archive.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("zip entry: %v", err)
+		}
+		defer rc.Close()
+		body, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("zip read: %v", err)
+		}
+		return string(body)
+	}
+	t.Fatalf("%s not found in archive", name)
+	return ""
+}
+
+func TestOfficeApplyKeepsRunFormatting(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    Format
+		entry     string
+		files     []string
+		corrected string
+		want      string
+	}{
+		{
+			name:   "docx runs",
+			format: FormatDOCX,
+			entry:  "word/document.xml",
+			files: []string{"word/document.xml", docxHeader +
+				`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Превет</w:t></w:r>` +
+				`<w:r><w:t xml:space="preserve"> мир, </w:t></w:r>` +
+				`<w:r><w:rPr><w:i/></w:rPr><w:t>как дила</w:t></w:r></w:p>` + docxFooter},
+			corrected: "Привет мир, как дела",
+			want: `<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Привет</w:t></w:r>` +
+				`<w:r><w:t xml:space="preserve"> мир, </w:t></w:r>` +
+				`<w:r><w:rPr><w:i/></w:rPr><w:t>как дела</w:t></w:r></w:p>`,
+		},
+		{
+			name:   "odt spans",
+			format: FormatODT,
+			entry:  "content.xml",
+			files: []string{
+				"mimetype", "application/vnd.oasis.opendocument.text",
+				"content.xml", odtHeader +
+					`<text:p>Превет <text:span text:style-name="B">мир</text:span> как дила</text:p>` + odtFooter,
+			},
+			corrected: "Привет мир, как дела",
+			// Вставка относится к предыдущему символу и получает его оформление
+			want: `<text:p>Привет <text:span text:style-name="B">мир,</text:span> как дела</text:p>`,
+		},
+		{
+			name:   "tab removed falls back to first run",
+			format: FormatDOCX,
+			entry:  "word/document.xml",
+			files: []string{"word/document.xml", docxHeader +
+				`<w:p><w:r><w:t>до</w:t><w:tab/><w:t>после</w:t></w:r></w:p>` + docxFooter},
+			corrected: "допосле",
+			want:      `<w:p><w:r><w:t>допосле</w:t><w:tab/><w:t></w:t></w:r></w:p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.format, buildZip(t, tt.files...))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			out, err := doc.Apply(tt.corrected)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+
+			content := readZipEntry(t, out, tt.entry)
+			if !strings.Contains(content, tt.want) {
+				t.Fatalf("%s:\n%s\nwant it to contain\n%s", tt.entry, content, tt.want)
+			}
+
+			// Исправленный файл читается обратно с тем же текстом
+			reparsed, err := Parse(tt.format, out)
+			if err != nil {
+				t.Fatalf("Parse corrected: %v", err)
+			}
+			if got := reparsed.Text(); got != tt.corrected {
+				t.Fatalf("Text after Apply = %q, want %q", got, tt.corrected)
+			}
+		})
+	}
+}
+
+func TestOfficeApplyKeepsUnchangedParagraphs(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">Всё  &amp; хорошо</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>Как дила</w:t></w:r></w:p>`
+	data := buildZip(t, "word/document.xml", docxHeader+body+docxFooter)
+
+	doc, err := Parse(FormatDOCX, data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	out, err := doc.Apply("Всё & хорошо\n\nКак дела")
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	content := readZipEntry(t, out, "word/document.xml")
+	want := `<w:p><w:r><w:t xml:space="preserve">Всё  &amp; хорошо</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>Как дела</w:t></w:r></w:p>`
+	if !strings.Contains(content, want) {
+		t.Fatalf("document.xml:\n%s\nwant it to contain\n%s", content, want)
+	}
+}
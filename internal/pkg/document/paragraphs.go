@@ -0,0 +1,128 @@
+package document
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"spell_bot/internal/pkg/diff"
+)
+
+// paragraphSeparator разделяет абзацы в тексте, отправляемом на проверку
+const paragraphSeparator = "\n\n"
+
+// splitCorrected раскладывает исправленный текст обратно по исходным абзацам.
+// Обычно модель сохраняет абзацы, и они сопоставляются один к одному.
+// Если абзацы объединены или разбиты, сопоставление идёт по пословному diff.
+func splitCorrected(paragraphs []string, corrected string) []string {
+	if parts, ok := splitParagraphs(paragraphs, corrected); ok {
+		return parts
+	}
+	return alignCorrected(paragraphs, corrected)
+}
+
+// splitParagraphs делит исправленный текст по разделителям абзацев; false,
+// если число абзацев не совпадает с исходным
+func splitParagraphs(paragraphs []string, corrected string) ([]string, bool) {
+	for _, p := range paragraphs {
+		if strings.Contains(p, paragraphSeparator) {
+			return nil, false
+		}
+	}
+
+	parts := strings.Split(strings.TrimSpace(corrected), paragraphSeparator)
+	if len(parts) != len(paragraphs) {
+		return nil, false
+	}
+	for i, part := range parts {
+		if part != paragraphs[i] {
+			parts[i] = normalizeParagraph(part)
+		}
+	}
+	return parts, true
+}
+
+// alignCorrected сопоставляет исправленный текст с абзацами по пословному diff:
+// исходные и вставленные слова относятся к абзацу, в котором находится
+// соответствующее место исходного текста
+func alignCorrected(paragraphs []string, corrected string) []string {
+	original := strings.Join(paragraphs, paragraphSeparator)
+
+	// Границы абзацев в байтах исходного текста
+	ends := make([]int, len(paragraphs))
+	offset := 0
+	for i, p := range paragraphs {
+		offset += len(p)
+		ends[i] = offset
+		offset += len(paragraphSeparator)
+	}
+
+	out := make([]strings.Builder, len(paragraphs))
+	pos, current := 0, 0
+
+	// advance переходит к абзацу, которому принадлежит позиция pos исходного текста
+	advance := func() {
+		for current < len(ends)-1 && pos > ends[current] {
+			current++
+		}
+	}
+
+	for _, seg := range diff.Words(original, corrected) {
+		switch seg.Op {
+		case diff.Insert:
+			advance()
+			text := seg.Text
+			// Знак препинания в начале абзаца, который модель поставила при
+			// слиянии абзацев, закрывает предыдущий абзац
+			if current > 0 && pos == ends[current]-len(paragraphs[current]) {
+				n := strings.IndexFunc(text, func(r rune) bool {
+					return unicode.IsSpace(r) || unicode.IsLetter(r) || unicode.IsDigit(r)
+				})
+				if n < 0 {
+					n = len(text)
+				}
+				out[current-1].WriteString(text[:n])
+				text = text[n:]
+			}
+			out[current].WriteString(text)
+		case diff.Delete:
+			pos += len(seg.Text)
+		case diff.Equal:
+			// Общий участок может пересекать границы абзацев
+			text := seg.Text
+			for text != "" {
+				advance()
+				n := len(text)
+				if current < len(ends)-1 {
+					if pos < ends[current] {
+						n = min(n, ends[current]-pos)
+					} else {
+						// Разделитель между абзацами не переносится в текст
+						n = min(n, ends[current]+len(paragraphSeparator)-pos)
+						pos += n
+						text = text[n:]
+						continue
+					}
+				}
+				out[current].WriteString(text[:n])
+				pos += n
+				text = text[n:]
+			}
+		}
+	}
+
+	result := make([]string, len(paragraphs))
+	for i := range out {
+		result[i] = normalizeParagraph(out[i].String())
+	}
+	return result
+}
+
+// lineBreak - перенос строки вместе с окружающими пробелами
+var lineBreak = regexp.MustCompile(`[ \t\r]*\n\s*`)
+
+// normalizeParagraph превращает переносы строк внутри абзаца, добавленные
+// моделью, в пробелы
+func normalizeParagraph(s string) string {
+	return strings.TrimSpace(lineBreak.ReplaceAllString(s, " "))
+}
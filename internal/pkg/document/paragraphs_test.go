@@ -0,0 +1,61 @@
+package document
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitCorrected(t *testing.T) {
+	paragraphs := []string{"Превет мир", "Как дила", "Всё хорошо"}
+
+	tests := []struct {
+		name      string
+		corrected string
+		want      []string
+	}{
+		{
+			name:      "paragraphs kept",
+			corrected: "Привет, мир\n\nКак дела?\n\nВсё хорошо",
+			want:      []string{"Привет, мир", "Как дела?", "Всё хорошо"},
+		},
+		{
+			name:      "line break inside paragraph",
+			corrected: "Привет,\nмир\n\nКак дела\n\nВсё хорошо",
+			want:      []string{"Привет, мир", "Как дела", "Всё хорошо"},
+		},
+		{
+			name:      "paragraphs merged",
+			corrected: "Привет, мир. Как дела?\n\nВсё хорошо",
+			want:      []string{"Привет, мир.", "Как дела?", "Всё хорошо"},
+		},
+		{
+			name:      "paragraph split",
+			corrected: "Привет\n\nмир\n\nКак дела\n\nВсё хорошо",
+			want:      []string{"Привет мир", "Как дела", "Всё хорошо"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitCorrected(paragraphs, tt.corrected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitCorrected = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitCorrectedLargeDocument(t *testing.T) {
+	// Около 50 тысяч символов с правкой в каждом абзаце
+	paragraphs := make([]string, 500)
+	fixed := make([]string, len(paragraphs))
+	for i := range paragraphs {
+		paragraphs[i] = strings.Repeat("слово ", 15) + "ашибка"
+		fixed[i] = strings.Repeat("слово ", 15) + "ошибка"
+	}
+
+	got := splitCorrected(paragraphs, strings.Join(fixed, paragraphSeparator))
+	if !reflect.DeepEqual(got, fixed) {
+		t.Error("paragraphs were not mapped one to one")
+	}
+}
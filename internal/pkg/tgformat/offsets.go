@@ -0,0 +1,116 @@
+package tgformat
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"spell_bot/internal/pkg/diff"
+)
+
+// offsetMap переводит позиции в рунах исходного текста в позиции исправленного.
+// Заменённый участок (удаление со вставкой) переносится целиком: его края
+// переходят в края замены. Чистая вставка на границе оформления остаётся
+// снаружи: добавленная после выделенного слова запятая не становится жирной.
+type offsetMap struct {
+	// before[i] - позиция перед вставками в точке i, after[i] - после них
+	before, after []int
+}
+
+func newOffsetMap(original, corrected string) offsetMap {
+	n := utf8.RuneCountInString(original)
+	m := offsetMap{before: make([]int, n+1), after: make([]int, n+1)}
+
+	segments := diff.Words(original, corrected)
+	pos, out := 0, 0
+	// insertedAt - позиция последней чистой вставки; before для неё уже задан
+	insertedAt := -1
+	for i := 0; i < len(segments); {
+		if segments[i].Op == diff.Equal {
+			for range segments[i].Text {
+				m.set(pos, out, insertedAt)
+				pos++
+				out++
+			}
+			i++
+			continue
+		}
+
+		// Подряд идущие удаления и вставки образуют одну замену
+		var deletedText, insertedText strings.Builder
+		for ; i < len(segments) && segments[i].Op != diff.Equal; i++ {
+			if segments[i].Op == diff.Delete {
+				deletedText.WriteString(segments[i].Text)
+			} else {
+				insertedText.WriteString(segments[i].Text)
+			}
+		}
+		deleted := utf8.RuneCountInString(deletedText.String())
+		inserted := utf8.RuneCountInString(insertedText.String())
+
+		if deleted == 0 {
+			m.before[pos], m.after[pos] = out, out+inserted
+			out += inserted
+			insertedAt = pos
+			continue
+		}
+
+		// Знаки препинания, добавленные по краям заменённого слова, тоже остаются снаружи
+		lead := max(punctuation(insertedText.String(), true)-punctuation(deletedText.String(), true), 0)
+		trail := max(punctuation(insertedText.String(), false)-punctuation(deletedText.String(), false), 0)
+		core := max(inserted-lead-trail, 0)
+
+		m.before[pos], m.after[pos] = out, out+lead
+		for j := 1; j < deleted; j++ {
+			m.before[pos+j] = out + lead + j*core/deleted
+			m.after[pos+j] = m.before[pos+j]
+		}
+		pos += deleted
+		out += inserted
+		m.before[pos], m.after[pos] = out-trail, out
+		insertedAt = pos
+	}
+
+	m.set(pos, out, insertedAt)
+
+	return m
+}
+
+// set сопоставляет позиции pos позицию out, сохраняя before вставки в этой точке
+func (m offsetMap) set(pos, out, insertedAt int) {
+	if pos != insertedAt {
+		m.before[pos] = out
+	}
+	m.after[pos] = out
+}
+
+// start переводит начало оформления
+func (m offsetMap) start(pos int) int {
+	return m.after[pos]
+}
+
+// end переводит конец оформления
+func (m offsetMap) end(pos int) int {
+	return m.before[pos]
+}
+
+// punctuation возвращает число знаков препинания и пробелов в начале (leading)
+// или в конце текста
+func punctuation(text string, leading bool) int {
+	isPunct := func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	}
+	runes := []rune(text)
+	n := 0
+	for n < len(runes) {
+		r := runes[n]
+		if !leading {
+			r = runes[len(runes)-1-n]
+		}
+		if !isPunct(r) {
+			break
+		}
+		n++
+	}
+	return n
+}
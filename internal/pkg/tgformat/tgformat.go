@@ -0,0 +1,347 @@
+package tgformat
+
+import (
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"spell_bot/internal/checker"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// protectedTypes - сущности, текст которых не является прозой и не исправляется
+var protectedTypes = map[string]bool{
+	"code":         true,
+	"pre":          true,
+	"url":          true,
+	"email":        true,
+	"mention":      true,
+	"hashtag":      true,
+	"cashtag":      true,
+	"bot_command":  true,
+	"phone_number": true,
+}
+
+// styleTags - теги Telegram HTML для оформления текста
+var styleTags = map[string]string{
+	"bold":          "b",
+	"italic":        "i",
+	"underline":     "u",
+	"strikethrough": "s",
+	"spoiler":       "tg-spoiler",
+	"blockquote":    "blockquote",
+	"text_link":     "a",
+	"text_mention":  "a",
+}
+
+// Text - текст сообщения с сущностями Telegram, подготовленный к проверке:
+// защищённые фрагменты заменены заполнителями, оформление хранится отдельно
+// и после проверки переносится на исправленный текст.
+type Text struct {
+	masked    string
+	fragments []string // HTML защищённых фрагментов по номеру заполнителя
+	plain     []string // исходный текст защищённых фрагментов
+	spans     []placeholderSpan
+	styles    []style
+}
+
+// placeholderSpan - положение заполнителя в рунах текста с заполнителями
+type placeholderSpan struct {
+	start, length int
+	// plain - длина исходного фрагмента в рунах
+	plain int
+}
+
+// style - оформление в рунах текста с заполнителями
+type style struct {
+	start, end int
+	open       string
+	close      string
+}
+
+// Parse разбирает текст сообщения и его сущности. Возвращает nil, если
+// сущностей, которые нужно сохранить, нет или текст уже содержит заполнители.
+func Parse(text string, entities []tgbotapi.MessageEntity) *Text {
+	if len(entities) == 0 || checker.HasPlaceholders(text) {
+		return nil
+	}
+
+	runes := []rune(text)
+	offsets := utf16Offsets(runes)
+
+	// Защищённые фрагменты не пересекаются: берём внешние по порядку
+	var protected []tgbotapi.MessageEntity
+	var formatting []tgbotapi.MessageEntity
+	for _, e := range entities {
+		switch {
+		case protectedTypes[e.Type]:
+			protected = append(protected, e)
+		case styleTags[e.Type] != "":
+			formatting = append(formatting, e)
+		}
+	}
+	if len(protected) == 0 && len(formatting) == 0 {
+		return nil
+	}
+	sort.SliceStable(protected, func(i, j int) bool {
+		return protected[i].Offset < protected[j].Offset
+	})
+
+	t := &Text{}
+	var masked strings.Builder
+	// shift[i] - позиция руны i исходного текста в тексте с заполнителями
+	shift := make([]int, len(runes)+1)
+	pos, out := 0, 0
+	type span struct{ start, end int }
+	var spans []span
+
+	for _, e := range protected {
+		start, end := offsets.rune(e.Offset), offsets.rune(e.Offset+e.Length)
+		if start < pos || start >= end {
+			continue
+		}
+		for ; pos < start; pos++ {
+			shift[pos] = out
+			masked.WriteRune(runes[pos])
+			out++
+		}
+
+		placeholder := checker.Placeholder(len(t.fragments))
+		fragment := string(runes[start:end])
+		t.plain = append(t.plain, fragment)
+		t.fragments = append(t.fragments, fragmentHTML(e, fragment))
+		for ; pos < end; pos++ {
+			shift[pos] = out
+		}
+		masked.WriteString(placeholder)
+		t.spans = append(t.spans, placeholderSpan{start: out, length: len([]rune(placeholder)), plain: end - start})
+		out += len([]rune(placeholder))
+		spans = append(spans, span{start, end})
+	}
+	for ; pos <= len(runes); pos++ {
+		shift[pos] = out
+		if pos < len(runes) {
+			masked.WriteRune(runes[pos])
+			out++
+		}
+	}
+	t.masked = masked.String()
+
+	for _, e := range formatting {
+		start, end := offsets.rune(e.Offset), offsets.rune(e.Offset+e.Length)
+		if start >= end {
+			continue
+		}
+		// Оформление внутри кода Telegram не поддерживает
+		inside := false
+		for _, s := range spans {
+			if start >= s.start && end <= s.end {
+				inside = true
+				break
+			}
+		}
+		if inside {
+			continue
+		}
+
+		// Граница внутри защищённого фрагмента сдвигается на его край
+		mappedEnd := shift[end]
+		for _, s := range spans {
+			if end > s.start && end < s.end {
+				mappedEnd = shift[s.end]
+			}
+		}
+		open, closeTag := styleHTML(e)
+		if open == "" {
+			continue
+		}
+		t.styles = append(t.styles, style{start: shift[start], end: mappedEnd, open: open, close: closeTag})
+	}
+
+	return t
+}
+
+// Masked возвращает текст для проверки: защищённые фрагменты заменены заполнителями
+func (t *Text) Masked() string {
+	return t.masked
+}
+
+// Restorable сообщает, сохранил ли исправленный текст каждый заполнитель ровно один раз
+func (t *Text) Restorable(corrected string) bool {
+	seen := make([]int, len(t.fragments))
+	for _, m := range checker.PlaceholderPattern.FindAllString(corrected, -1) {
+		n, ok := t.placeholderIndex(m)
+		if !ok {
+			return false
+		}
+		seen[n]++
+	}
+	for _, count := range seen {
+		if count != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Unmask возвращает исходный текст защищённых фрагментов на место заполнителей
+func (t *Text) Unmask(s string) string {
+	return checker.PlaceholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if n, ok := t.placeholderIndex(m); ok {
+			return t.plain[n]
+		}
+		return m
+	})
+}
+
+// UnmaskOffset переводит смещение в рунах текста с заполнителями в смещение
+// исходного текста; false, если смещение попадает внутрь заполнителя
+func (t *Text) UnmaskOffset(pos int) (int, bool) {
+	delta := 0
+	for _, s := range t.spans {
+		if pos <= s.start {
+			break
+		}
+		if pos < s.start+s.length {
+			return 0, false
+		}
+		delta += s.plain - s.length
+	}
+	return pos + delta, true
+}
+
+// HTML выводит исправленный текст в Telegram HTML: оформление переносится
+// с исходного текста по пословному diff, заполнители заменяются исходными фрагментами
+func (t *Text) HTML(corrected string) string {
+	runes := []rune(corrected)
+	mapping := newOffsetMap(t.masked, corrected)
+
+	type tag struct {
+		pos    int
+		length int
+		order  int
+		html   string
+		open   bool
+	}
+	var tags []tag
+	for i, s := range t.styles {
+		start, end := mapping.start(s.start), mapping.end(s.end)
+		if start >= end {
+			continue
+		}
+		tags = append(tags,
+			tag{pos: start, length: end - start, order: i, html: s.open, open: true},
+			tag{pos: end, length: end - start, order: i, html: s.close},
+		)
+	}
+	// На одной позиции сначала закрываются теги (внутренние раньше внешних),
+	// затем открываются (внешние раньше внутренних)
+	sort.SliceStable(tags, func(i, j int) bool {
+		a, b := tags[i], tags[j]
+		if a.pos != b.pos {
+			return a.pos < b.pos
+		}
+		if a.open != b.open {
+			return !a.open
+		}
+		if a.length != b.length {
+			if a.open {
+				return a.length > b.length
+			}
+			return a.length < b.length
+		}
+		if a.open {
+			return a.order < b.order
+		}
+		return a.order > b.order
+	})
+
+	placeholders := make(map[int][2]int) // начало в рунах -> длина, номер фрагмента
+	for _, loc := range checker.PlaceholderPattern.FindAllStringIndex(corrected, -1) {
+		if n, ok := t.placeholderIndex(corrected[loc[0]:loc[1]]); ok {
+			start := len([]rune(corrected[:loc[0]]))
+			placeholders[start] = [2]int{len([]rune(corrected[loc[0]:loc[1]])), n}
+		}
+	}
+
+	var b strings.Builder
+	next := 0
+	for i := 0; i <= len(runes); i++ {
+		for next < len(tags) && tags[next].pos <= i {
+			b.WriteString(tags[next].html)
+			next++
+		}
+		if i == len(runes) {
+			break
+		}
+		if p, ok := placeholders[i]; ok {
+			b.WriteString(t.fragments[p[1]])
+			i += p[0] - 1
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+	}
+
+	return b.String()
+}
+
+func (t *Text) placeholderIndex(placeholder string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(placeholder, "⟦"), "⟧"))
+	if err != nil || n < 0 || n >= len(t.fragments) {
+		return 0, false
+	}
+	return n, true
+}
+
+// fragmentHTML выводит защищённый фрагмент в Telegram HTML
+func fragmentHTML(e tgbotapi.MessageEntity, text string) string {
+	escaped := html.EscapeString(text)
+	switch e.Type {
+	case "code":
+		return "<code>" + escaped + "</code>"
+	case "pre":
+		if e.Language != "" {
+			return `<pre><code class="language-` + html.EscapeString(e.Language) + `">` + escaped + "</code></pre>"
+		}
+		return "<pre>" + escaped + "</pre>"
+	default:
+		// Ссылки, упоминания и хештеги Telegram распознаёт сам
+		return escaped
+	}
+}
+
+// styleHTML возвращает открывающий и закрывающий теги оформления
+func styleHTML(e tgbotapi.MessageEntity) (string, string) {
+	name := styleTags[e.Type]
+	switch e.Type {
+	case "text_link":
+		return `<a href="` + html.EscapeString(e.URL) + `">`, "</a>"
+	case "text_mention":
+		if e.User == nil {
+			return "", ""
+		}
+		return `<a href="tg://user?id=` + strconv.FormatInt(e.User.ID, 10) + `">`, "</a>"
+	default:
+		return "<" + name + ">", "</" + name + ">"
+	}
+}
+
+// runeOffsets переводит смещения Telegram в UTF-16 в индексы рун
+type runeOffsets []int
+
+func utf16Offsets(runes []rune) runeOffsets {
+	// offsets[i] - смещение руны i в UTF-16
+	offsets := make(runeOffsets, len(runes)+1)
+	for i, r := range runes {
+		offsets[i+1] = offsets[i] + max(utf16.RuneLen(r), 1)
+	}
+	return offsets
+}
+
+// rune возвращает индекс первой руны, начинающейся не раньше смещения offset
+func (o runeOffsets) rune(offset int) int {
+	return sort.SearchInts(o, offset)
+}
@@ -0,0 +1,75 @@
+package tgformat
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// codeText - текст с кодом `fmt.Println` в середине (смещения в UTF-16)
+func codeText(t *testing.T) (*Text, string) {
+	t.Helper()
+
+	text := "Вызови fmt.Println и провирь вывод"
+	parsed := Parse(text, []tgbotapi.MessageEntity{{Type: "code", Offset: 7, Length: 11}})
+	if parsed == nil {
+		t.Fatal("Parse returned nil")
+	}
+	return parsed, text
+}
+
+func TestParseMasksProtectedFragments(t *testing.T) {
+	parsed, text := codeText(t)
+
+	if got, want := parsed.Masked(), "Вызови ⟦0⟧ и провирь вывод"; got != want {
+		t.Errorf("Masked = %q, want %q", got, want)
+	}
+	if got := parsed.Unmask(parsed.Masked()); got != text {
+		t.Errorf("Unmask = %q, want %q", got, text)
+	}
+	if !parsed.Restorable("Вызови ⟦0⟧ и проверь вывод") {
+		t.Error("text with the placeholder is not restorable")
+	}
+	if parsed.Restorable("Вызови код и проверь вывод") {
+		t.Error("text without the placeholder is restorable")
+	}
+}
+
+func TestUnmaskOffset(t *testing.T) {
+	parsed, _ := codeText(t)
+
+	tests := []struct {
+		pos  int
+		want int
+		ok   bool
+	}{
+		{0, 0, true},
+		{7, 7, true},   // начало заполнителя
+		{8, 0, false},  // внутри заполнителя
+		{10, 18, true}, // сразу после заполнителя
+		{13, 21, true}, // "провирь"
+		{20, 28, true},
+	}
+	for _, tt := range tests {
+		got, ok := parsed.UnmaskOffset(tt.pos)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("UnmaskOffset(%d) = %d, %v; want %d, %v", tt.pos, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHTMLRestoresFormatting(t *testing.T) {
+	text := "Жирный текст и ссылка"
+	parsed := Parse(text, []tgbotapi.MessageEntity{
+		{Type: "bold", Offset: 0, Length: 6},
+		{Type: "url", Offset: 15, Length: 6},
+	})
+	if parsed == nil {
+		t.Fatal("Parse returned nil")
+	}
+
+	got := parsed.HTML("Жирный текст, и ⟦0⟧")
+	if want := "<b>Жирный</b> текст, и ссылка"; got != want {
+		t.Errorf("HTML = %q, want %q", got, want)
+	}
+}
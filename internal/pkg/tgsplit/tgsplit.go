@@ -0,0 +1,197 @@
+package tgsplit
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MessageLimit - максимальная длина текста сообщения Telegram в UTF-16 единицах
+const MessageLimit = 4096
+
+// numberReserve - место под нумерацию частей вида "(12/34)\n"
+const numberReserve = 16
+
+type tokenKind int
+
+const (
+	tokenText tokenKind = iota
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind tokenKind
+	raw  string
+	name string
+}
+
+// SplitHTML делит сообщение в Telegram HTML на части не длиннее limit.
+// Теги и HTML-сущности никогда не разрезаются; открытые на границе теги
+// (например <code>) закрываются в конце части и открываются заново в следующей.
+// Если частей больше одной, каждая нумеруется: (1/3), (2/3)...
+// Длина считается по исходному HTML, то есть с запасом относительно лимита Telegram.
+func SplitHTML(text string, limit int) []string {
+	if limit <= 0 {
+		limit = MessageLimit
+	}
+	if utf16Len(text) <= limit {
+		return []string{text}
+	}
+
+	tokens := tokenize(text)
+	budget := max(limit-numberReserve, 1)
+
+	var (
+		parts []string
+		open  []token
+		i     int
+	)
+
+	for i < len(tokens) {
+		var b strings.Builder
+		for _, t := range open {
+			b.WriteString(t.raw)
+		}
+		stack := append([]token(nil), open...)
+		size := utf16Len(b.String())
+		start := i
+
+		type breakPoint struct {
+			index int
+			len   int
+			stack []token
+		}
+		var lastNewline, lastSpace *breakPoint
+
+		for i < len(tokens) {
+			t := tokens[i]
+			next := apply(stack, t)
+			if i > start && size+utf16Len(t.raw)+closingLen(next) > budget {
+				break
+			}
+
+			stack = next
+			b.WriteString(t.raw)
+			size += utf16Len(t.raw)
+			i++
+
+			if t.kind == tokenText {
+				bp := &breakPoint{index: i, len: b.Len(), stack: append([]token(nil), stack...)}
+				switch t.raw {
+				case "\n":
+					lastNewline = bp
+				case " ":
+					lastSpace = bp
+				}
+			}
+		}
+
+		part := b.String()
+		if i < len(tokens) {
+			// Режем по последнему переводу строки или пробелу, если он не слишком близко к началу
+			for _, bp := range []*breakPoint{lastNewline, lastSpace} {
+				if bp != nil && bp.len > len(part)/2 {
+					i = bp.index
+					part = part[:bp.len]
+					stack = bp.stack
+					break
+				}
+			}
+		}
+
+		for j := len(stack) - 1; j >= 0; j-- {
+			part += "</" + stack[j].name + ">"
+		}
+		parts = append(parts, part)
+		open = stack
+	}
+
+	if len(parts) > 1 {
+		for n := range parts {
+			parts[n] = fmt.Sprintf("(%d/%d)\n%s", n+1, len(parts), parts[n])
+		}
+	}
+
+	return parts
+}
+
+// apply возвращает стек открытых тегов после токена t
+func apply(stack []token, t token) []token {
+	switch t.kind {
+	case tokenOpen:
+		return append(stack[:len(stack):len(stack)], t)
+	case tokenClose:
+		for j := len(stack) - 1; j >= 0; j-- {
+			if stack[j].name == t.name {
+				return append(stack[:j:j], stack[j+1:]...)
+			}
+		}
+	}
+	return stack
+}
+
+func closingLen(stack []token) int {
+	n := 0
+	for _, t := range stack {
+		n += len(t.name) + 3
+	}
+	return n
+}
+
+// tokenize разбивает HTML на теги, сущности (&amp; и т.п.) и отдельные символы
+func tokenize(s string) []token {
+	var tokens []token
+	for len(s) > 0 {
+		switch s[0] {
+		case '<':
+			if end := strings.IndexByte(s, '>'); end > 0 {
+				raw := s[:end+1]
+				tokens = append(tokens, tagToken(raw))
+				s = s[end+1:]
+				continue
+			}
+		case '&':
+			if end := strings.IndexByte(s, ';'); end > 0 && end <= 10 && !strings.ContainsAny(s[1:end], " \n<&") {
+				tokens = append(tokens, token{kind: tokenText, raw: s[:end+1]})
+				s = s[end+1:]
+				continue
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(s)
+		tokens = append(tokens, token{kind: tokenText, raw: s[:size]})
+		s = s[size:]
+	}
+	return tokens
+}
+
+func tagToken(raw string) token {
+	inner := strings.TrimSpace(raw[1 : len(raw)-1])
+	kind := tokenOpen
+	if strings.HasPrefix(inner, "/") {
+		kind = tokenClose
+		inner = strings.TrimSpace(inner[1:])
+	}
+	if strings.HasSuffix(inner, "/") {
+		// Самозакрывающиеся теги не влияют на стек
+		return token{kind: tokenText, raw: raw}
+	}
+	name := inner
+	if i := strings.IndexAny(inner, " \t\n"); i >= 0 {
+		name = inner[:i]
+	}
+	return token{kind: kind, raw: raw, name: strings.ToLower(name)}
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
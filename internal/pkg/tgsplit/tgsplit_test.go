@@ -0,0 +1,142 @@
+package tgsplit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var (
+	prefixPattern = regexp.MustCompile(`^\(\d+/\d+\)\n`)
+	tagPattern    = regexp.MustCompile(`<[^<>]*>`)
+	entityPattern = regexp.MustCompile(`&(amp|lt|gt|quot);`)
+)
+
+// plain возвращает текст части без нумерации и тегов
+func plain(part string) string {
+	return tagPattern.ReplaceAllString(prefixPattern.ReplaceAllString(part, ""), "")
+}
+
+// checkParts проверяет общие гарантии SplitHTML: лимит, нумерацию, целые теги
+// и сущности и то, что текст частей складывается в исходный
+func checkParts(t *testing.T, text string, limit int, parts []string) {
+	t.Helper()
+
+	var joined strings.Builder
+	for i, part := range parts {
+		if n := utf16Len(part); n > limit {
+			t.Errorf("part %d: %d UTF-16 units, limit %d", i+1, n, limit)
+		}
+		if len(parts) > 1 {
+			if prefix := fmt.Sprintf("(%d/%d)\n", i+1, len(parts)); !strings.HasPrefix(part, prefix) {
+				t.Errorf("part %d does not start with %q: %.30q", i+1, prefix, part)
+			}
+		}
+
+		body := tagPattern.ReplaceAllString(part, "")
+		if strings.ContainsAny(body, "<>") {
+			t.Errorf("part %d has a cut tag: %q", i+1, part)
+		}
+		if strings.Count(body, "&") != len(entityPattern.FindAllString(body, -1)) {
+			t.Errorf("part %d has a cut entity: %q", i+1, part)
+		}
+		joined.WriteString(plain(part))
+	}
+
+	if got, want := joined.String(), tagPattern.ReplaceAllString(text, ""); got != want {
+		t.Errorf("parts text differs from the source:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestSplitHTMLShortText(t *testing.T) {
+	text := "<b>Привет</b>, мир &amp; все"
+	parts := SplitHTML(text, 100)
+	if len(parts) != 1 || parts[0] != text {
+		t.Errorf("parts = %q, want the text unchanged", parts)
+	}
+}
+
+func TestSplitHTMLCyrillicAtLimit(t *testing.T) {
+	text := strings.Repeat("ж", MessageLimit)
+	if parts := SplitHTML(text, 0); len(parts) != 1 || parts[0] != text {
+		t.Fatalf("text of exactly %d runes was split into %d parts", MessageLimit, len(parts))
+	}
+
+	text += "ж"
+	parts := SplitHTML(text, 0)
+	if len(parts) != 2 {
+		t.Fatalf("parts = %d, want 2", len(parts))
+	}
+	checkParts(t, text, MessageLimit, parts)
+}
+
+func TestSplitHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+	}{
+		{"words", strings.Repeat("слово ", 100), 80},
+		{"lines", strings.Repeat("строка текста\n", 50), 80},
+		{"entities", strings.Repeat("a &amp; b &lt;c&gt; &quot;d&quot; ", 40), 60},
+		{"tags", strings.Repeat("<b>жирный</b> <i>курсив</i> ", 40), 60},
+		{"no spaces", strings.Repeat("абв&amp;", 100), 50},
+		{"emoji", strings.Repeat("😀 текст ", 60), 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := SplitHTML(tt.text, tt.limit)
+			if len(parts) < 2 {
+				t.Fatalf("parts = %d, want several", len(parts))
+			}
+			checkParts(t, tt.text, tt.limit, parts)
+		})
+	}
+}
+
+func TestSplitHTMLReopensTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		open  string
+		close string
+	}{
+		{"bold", "<b>", "</b>"},
+		{"code", "<code>", "</code>"},
+		{"pre", `<pre><code class="language-go">`, "</code></pre>"},
+		{"link", `<a href="https://example.com/?a=1&amp;b=2">`, "</a>"},
+		{"nested", "<b><i>", "</i></b>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := "Начало " + tt.open + strings.Repeat("внутри тега ", 40) + tt.close + " конец"
+			parts := SplitHTML(text, 100)
+			if len(parts) < 3 {
+				t.Fatalf("parts = %d, want at least 3", len(parts))
+			}
+			checkParts(t, text, 100, parts)
+
+			for i, part := range parts {
+				body := prefixPattern.ReplaceAllString(part, "")
+				if i > 0 && !strings.HasPrefix(body, tt.open) {
+					t.Errorf("part %d does not reopen %s: %.40q", i+1, tt.open, body)
+				}
+				if i < len(parts)-1 && !strings.HasSuffix(body, tt.close) {
+					t.Errorf("part %d does not close %s: %q", i+1, tt.close, body)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitHTMLPrefersLineBreaks(t *testing.T) {
+	line := "первая строка абзаца\n"
+	parts := SplitHTML(strings.Repeat(line, 20), 100)
+	for i, part := range parts[:len(parts)-1] {
+		if !strings.HasSuffix(part, "\n") {
+			t.Errorf("part %d is not cut at a line break: %q", i+1, part)
+		}
+	}
+}
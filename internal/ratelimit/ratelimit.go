@@ -0,0 +1,217 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"spell_bot/internal/storage"
+)
+
+// Limits - ограничения на пользователя. Нулевое значение поля отключает соответствующий лимит.
+type Limits struct {
+	PerMinute     float64 // скорость пополнения token bucket, запросов в минуту
+	Burst         int     // ёмкость token bucket
+	DailyRequests int     // проверок в сутки (UTC)
+	DailyChars    int     // символов в сутки (UTC)
+}
+
+// Reason - причина отказа
+type Reason int
+
+const (
+	ReasonNone Reason = iota
+	ReasonRate
+	ReasonDailyRequests
+	ReasonDailyChars
+)
+
+func (r Reason) String() string {
+	switch r {
+	case ReasonNone:
+		return "none"
+	case ReasonRate:
+		return "rate"
+	case ReasonDailyRequests:
+		return "daily_requests"
+	case ReasonDailyChars:
+		return "daily_chars"
+	default:
+		return "unknown"
+	}
+}
+
+// Decision - результат проверки лимитов
+type Decision struct {
+	Allowed bool
+	Reason  Reason
+	// RetryAt - момент, после которого запрос будет разрешён
+	RetryAt time.Time
+}
+
+// Limiter ограничивает частоту запросов пользователя (token bucket в памяти)
+// и дневные квоты (счётчики в storage.Storage). Администраторы не ограничиваются.
+type Limiter struct {
+	storage storage.Storage
+
+	mu      sync.Mutex
+	limits  Limits
+	admins  map[int64]bool
+	buckets map[int64]*bucket
+
+	now func() time.Time
+}
+
+// maxIdleBuckets - после этого числа заполненные (неактивные) корзины удаляются
+const maxIdleBuckets = 10000
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewLimiter(storage storage.Storage, limits Limits, admins []int64) *Limiter {
+	l := &Limiter{
+		storage: storage,
+		limits:  limits,
+		admins:  make(map[int64]bool, len(admins)),
+		buckets: make(map[int64]*bucket),
+		now:     time.Now,
+	}
+	for _, id := range admins {
+		l.admins[id] = true
+	}
+	return l
+}
+
+// IsAdmin сообщает, является ли пользователь администратором
+func (l *Limiter) IsAdmin(telegramID int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.admins[telegramID]
+}
+
+// Limits возвращает текущие ограничения
+func (l *Limiter) Limits() Limits {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.limits
+}
+
+// SetLimits заменяет ограничения без перезапуска
+func (l *Limiter) SetLimits(limits Limits) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limits = limits
+	l.buckets = make(map[int64]*bucket)
+}
+
+// Allow проверяет лимиты для текста длиной chars символов и, если запрос
+// разрешён, списывает его из token bucket и дневной квоты. Квота резервируется
+// в хранилище одной атомарной операцией, поэтому параллельные запросы её не превышают.
+// При ошибке хранилища запрос разрешается (ограничение частоты продолжает действовать),
+// а ошибка возвращается вместе с решением.
+func (l *Limiter) Allow(ctx context.Context, telegramID int64, chars int) (Decision, error) {
+	const op = "ratelimit.Limiter.Allow"
+
+	if l.IsAdmin(telegramID) {
+		return Decision{Allowed: true}, nil
+	}
+
+	limits := l.Limits()
+	now := l.now().UTC()
+	day := now.Format(time.DateOnly)
+	resetAt := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+
+	if retryAt, ok := l.take(telegramID, limits, now); !ok {
+		return Decision{Reason: ReasonRate, RetryAt: retryAt}, nil
+	}
+
+	if limits.DailyRequests <= 0 && limits.DailyChars <= 0 {
+		return Decision{Allowed: true}, nil
+	}
+
+	reserved, err := l.storage.ReserveDailyUsage(ctx, telegramID, day, 1, chars, limits.DailyRequests, limits.DailyChars)
+	if err != nil {
+		return Decision{Allowed: true}, fmt.Errorf("%s: %w", op, err)
+	}
+	if reserved {
+		return Decision{Allowed: true}, nil
+	}
+
+	// Запрос не выполняется - возвращаем токен, чтобы отказ по квоте не расходовал частоту
+	l.refund(telegramID, limits)
+
+	reason := ReasonDailyChars
+	if limits.DailyRequests > 0 {
+		usage, err := l.storage.GetDailyUsage(ctx, telegramID, day)
+		if err != nil {
+			return Decision{Reason: ReasonDailyRequests, RetryAt: resetAt}, fmt.Errorf("%s: %w", op, err)
+		}
+		if usage.Requests+1 > limits.DailyRequests {
+			reason = ReasonDailyRequests
+		}
+	}
+
+	return Decision{Reason: reason, RetryAt: resetAt}, nil
+}
+
+// take списывает токен из корзины пользователя
+func (l *Limiter) take(telegramID int64, limits Limits, now time.Time) (time.Time, bool) {
+	if limits.PerMinute <= 0 || limits.Burst <= 0 {
+		return time.Time{}, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	perSecond := limits.PerMinute / 60
+	capacity := float64(limits.Burst)
+
+	b, ok := l.buckets[telegramID]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.prune(now, perSecond, capacity)
+		}
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[telegramID] = b
+	}
+
+	b.tokens = min(capacity, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+		return now.Add(wait), false
+	}
+
+	b.tokens--
+	return time.Time{}, true
+}
+
+// refund возвращает токен, списанный take
+func (l *Limiter) refund(telegramID int64, limits Limits) {
+	if limits.PerMinute <= 0 || limits.Burst <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[telegramID]; ok {
+		b.tokens = min(float64(limits.Burst), b.tokens+1)
+	}
+}
+
+// prune удаляет корзины, которые уже успели полностью наполниться
+func (l *Limiter) prune(now time.Time, perSecond, capacity float64) {
+	for id, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*perSecond >= capacity {
+			delete(l.buckets, id)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"spell_bot/internal/storage/memory"
+)
+
+const (
+	userID  int64 = 1
+	adminID int64 = 2
+)
+
+// start - 02:59 по Москве, то есть 23:59 UTC: до сброса квот остаётся минута
+var start = time.Date(2024, 1, 2, 2, 59, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+// fakeClock - управляемые часы для Limiter
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLimiter(t *testing.T, limits Limits) (*Limiter, *fakeClock) {
+	t.Helper()
+
+	clock := &fakeClock{t: start}
+	l := NewLimiter(memory.NewStorage(), limits, []int64{adminID})
+	l.now = clock.now
+	return l, clock
+}
+
+func TestAllow(t *testing.T) {
+	type step struct {
+		advance time.Duration
+		user    int64
+		chars   int
+		want    Reason // ReasonNone - запрос разрешён
+	}
+
+	tests := []struct {
+		name   string
+		limits Limits
+		steps  []step
+	}{
+		{
+			name:   "burst",
+			limits: Limits{PerMinute: 6, Burst: 3},
+			steps: []step{
+				{chars: 10, want: ReasonNone},
+				{chars: 10, want: ReasonNone},
+				{chars: 10, want: ReasonNone},
+				{chars: 10, want: ReasonRate},
+			},
+		},
+		{
+			name:   "bucket refill",
+			limits: Limits{PerMinute: 60, Burst: 1},
+			steps: []step{
+				{chars: 10, want: ReasonNone},
+				{chars: 10, want: ReasonRate},
+				{advance: time.Second, chars: 10, want: ReasonNone},
+				{advance: 500 * time.Millisecond, chars: 10, want: ReasonRate},
+				{advance: 500 * time.Millisecond, chars: 10, want: ReasonNone},
+			},
+		},
+		{
+			name:   "daily requests reset at UTC midnight",
+			limits: Limits{DailyRequests: 2},
+			steps: []step{
+				{chars: 10, want: ReasonNone},
+				{chars: 10, want: ReasonNone},
+				{chars: 10, want: ReasonDailyRequests},
+				{advance: 59 * time.Second, chars: 10, want: ReasonDailyRequests},
+				{advance: time.Second, chars: 10, want: ReasonNone},
+			},
+		},
+		{
+			name:   "daily chars",
+			limits: Limits{DailyChars: 100},
+			steps: []step{
+				{chars: 60, want: ReasonNone},
+				{chars: 50, want: ReasonDailyChars},
+				{chars: 40, want: ReasonNone},
+				{chars: 1, want: ReasonDailyChars},
+			},
+		},
+		{
+			name:   "daily denial refunds rate token",
+			limits: Limits{PerMinute: 1, Burst: 2, DailyChars: 100},
+			steps: []step{
+				{chars: 100, want: ReasonNone},
+				{chars: 10, want: ReasonDailyChars},
+				{chars: 0, want: ReasonNone},
+				{chars: 0, want: ReasonRate},
+			},
+		},
+		{
+			name:   "admin bypass",
+			limits: Limits{PerMinute: 1, Burst: 1, DailyRequests: 1, DailyChars: 10},
+			steps: []step{
+				{user: adminID, chars: 1000, want: ReasonNone},
+				{user: adminID, chars: 1000, want: ReasonNone},
+				{user: adminID, chars: 1000, want: ReasonNone},
+				{chars: 5, want: ReasonNone},
+				{chars: 5, want: ReasonRate},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, clock := newTestLimiter(t, tt.limits)
+
+			for i, s := range tt.steps {
+				clock.advance(s.advance)
+				user := s.user
+				if user == 0 {
+					user = userID
+				}
+
+				got, err := l.Allow(context.Background(), user, s.chars)
+				if err != nil {
+					t.Fatalf("step %d: Allow: %v", i, err)
+				}
+				if got.Allowed != (s.want == ReasonNone) || got.Reason != s.want {
+					t.Fatalf("step %d: got %+v, want reason %v", i, got, s.want)
+				}
+
+				now := clock.now()
+				switch s.want {
+				case ReasonRate:
+					if !got.RetryAt.After(now) {
+						t.Fatalf("step %d: RetryAt = %v, want after %v", i, got.RetryAt, now)
+					}
+				case ReasonDailyRequests, ReasonDailyChars:
+					midnight := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+					if !got.RetryAt.Equal(midnight) {
+						t.Fatalf("step %d: RetryAt = %v, want %v", i, got.RetryAt, midnight)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAllowConcurrentDailyQuota(t *testing.T) {
+	l, _ := newTestLimiter(t, Limits{DailyRequests: 10})
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			d, err := l.Allow(context.Background(), userID, 10)
+			if err != nil {
+				t.Errorf("Allow: %v", err)
+				return
+			}
+			if d.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 10 {
+		t.Fatalf("allowed %d concurrent requests, want 10", allowed)
+	}
+
+	usage, err := l.storage.GetDailyUsage(context.Background(), userID, "2024-01-01")
+	if err != nil {
+		t.Fatalf("GetDailyUsage: %v", err)
+	}
+	if usage.Requests != 10 || usage.Characters != 100 {
+		t.Fatalf("usage = %+v, want 10 requests and 100 characters", usage)
+	}
+}
+
+// failingStorage - хранилище, которое не может зарезервировать квоту
+type failingStorage struct {
+	*memory.Storage
+}
+
+var errStorage = errors.New("storage unavailable")
+
+func (failingStorage) ReserveDailyUsage(context.Context, int64, string, int, int, int, int) (bool, error) {
+	return false, errStorage
+}
+
+func TestAllowStorageErrorKeepsRateLimit(t *testing.T) {
+	clock := &fakeClock{t: start}
+	l := NewLimiter(failingStorage{memory.NewStorage()}, Limits{PerMinute: 1, Burst: 1, DailyRequests: 10}, nil)
+	l.now = clock.now
+
+	got, err := l.Allow(context.Background(), userID, 10)
+	if !errors.Is(err, errStorage) {
+		t.Fatalf("Allow: err = %v, want %v", err, errStorage)
+	}
+	if !got.Allowed {
+		t.Fatalf("Allow with storage error: got %+v, want allowed", got)
+	}
+
+	// Токен списан: сбой квот не отключает ограничение частоты
+	got, err = l.Allow(context.Background(), userID, 10)
+	if err != nil || got.Reason != ReasonRate {
+		t.Fatalf("Allow after storage error: got %+v, %v, want rate limit", got, err)
+	}
+}
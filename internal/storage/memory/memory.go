@@ -0,0 +1,329 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"spell_bot/internal/entity"
+	"spell_bot/internal/storage"
+)
+
+// Storage - потокобезопасное хранилище в памяти для тестов и эфемерных запусков.
+// Повторяет семантику SQL-бэкендов: UPSERT пользователя по chat_id,
+// уникальность telegram_id, автоинкрементные ID.
+type Storage struct {
+	mu sync.RWMutex
+
+	users      map[int64]*entity.User // по chat_id
+	lastUserID int64
+
+	checks      map[int64]*entity.Check // по ID
+	lastCheckID int64
+
+	settings     map[int64]*entity.UserSettings // по telegram_id
+	chatSettings map[int64]*entity.ChatSettings // по chat_id
+
+	usage map[usageKey]*entity.DailyUsage
+}
+
+type usageKey struct {
+	telegramID int64
+	day        string
+}
+
+var _ storage.Storage = (*Storage)(nil)
+
+func NewStorage() *Storage {
+	return &Storage{
+		users:        make(map[int64]*entity.User),
+		checks:       make(map[int64]*entity.Check),
+		settings:     make(map[int64]*entity.UserSettings),
+		chatSettings: make(map[int64]*entity.ChatSettings),
+		usage:        make(map[usageKey]*entity.DailyUsage),
+	}
+}
+
+// SaveUser сохраняет пользователя или обновляет если уже существует (UPSERT)
+func (s *Storage) SaveUser(ctx context.Context, user *entity.User) error {
+	const op = "storage.memory.SaveUser"
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for chatID, u := range s.users {
+		if u.TelegramID == user.TelegramID && chatID != user.ChatID {
+			return fmt.Errorf("%s: telegram_id %d already belongs to chat %d", op, user.TelegramID, chatID)
+		}
+	}
+
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	user.UpdatedAt = now
+
+	if existing, ok := s.users[user.ChatID]; ok {
+		existing.TelegramID = user.TelegramID
+		existing.Username = user.Username
+		existing.FirstName = user.FirstName
+		existing.LastName = user.LastName
+		existing.UpdatedAt = user.UpdatedAt
+		user.ID = existing.ID
+		return nil
+	}
+
+	s.lastUserID++
+	user.ID = s.lastUserID
+
+	stored := *user
+	s.users[user.ChatID] = &stored
+
+	return nil
+}
+
+// SaveCheck сохраняет результат проверки текста
+func (s *Storage) SaveCheck(ctx context.Context, check *entity.Check) error {
+	const op = "storage.memory.SaveCheck"
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if check.CreatedAt.IsZero() {
+		check.CreatedAt = time.Now()
+	}
+
+	s.lastCheckID++
+	check.ID = s.lastCheckID
+
+	stored := *check
+	s.checks[check.ID] = &stored
+
+	return nil
+}
+
+// GetCheck возвращает проверку по ID
+func (s *Storage) GetCheck(ctx context.Context, id int64) (*entity.Check, error) {
+	const op = "storage.memory.GetCheck"
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	check, ok := s.checks[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+
+	result := *check
+	return &result, nil
+}
+
+// ListChecks возвращает проверки пользователя от новых к старым
+func (s *Storage) ListChecks(ctx context.Context, telegramID int64, limit, offset int) ([]*entity.Check, error) {
+	const op = "storage.memory.ListChecks"
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var checks []*entity.Check
+	for _, c := range s.checks {
+		if c.TelegramID == telegramID {
+			check := *c
+			checks = append(checks, &check)
+		}
+	}
+
+	sort.Slice(checks, func(i, j int) bool {
+		if !checks[i].CreatedAt.Equal(checks[j].CreatedAt) {
+			return checks[i].CreatedAt.After(checks[j].CreatedAt)
+		}
+		return checks[i].ID > checks[j].ID
+	})
+
+	if offset >= len(checks) {
+		return nil, nil
+	}
+	checks = checks[offset:]
+	if limit >= 0 && limit < len(checks) {
+		checks = checks[:limit]
+	}
+
+	return checks, nil
+}
+
+// CountChecks возвращает число проверок пользователя
+func (s *Storage) CountChecks(ctx context.Context, telegramID int64) (int, error) {
+	const op = "storage.memory.CountChecks"
+
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, c := range s.checks {
+		if c.TelegramID == telegramID {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Ping всегда успешен: хранилище в памяти доступно, пока жив процесс
+func (s *Storage) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *Storage) Close() error {
+	return nil
+}
+
+// GetUserSettings возвращает настройки пользователя
+func (s *Storage) GetUserSettings(ctx context.Context, telegramID int64) (*entity.UserSettings, error) {
+	const op = "storage.memory.GetUserSettings"
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	settings, ok := s.settings[telegramID]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+
+	result := *settings
+	return &result, nil
+}
+
+// SaveUserSettings сохраняет настройки пользователя (UPSERT)
+func (s *Storage) SaveUserSettings(ctx context.Context, settings *entity.UserSettings) error {
+	const op = "storage.memory.SaveUserSettings"
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings.UpdatedAt = time.Now()
+
+	stored := *settings
+	s.settings[settings.TelegramID] = &stored
+
+	return nil
+}
+
+// GetChatSettings возвращает настройки группового чата
+func (s *Storage) GetChatSettings(ctx context.Context, chatID int64) (*entity.ChatSettings, error) {
+	const op = "storage.memory.GetChatSettings"
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	settings, ok := s.chatSettings[chatID]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+
+	result := *settings
+	return &result, nil
+}
+
+// SaveChatSettings сохраняет настройки группового чата (UPSERT)
+func (s *Storage) SaveChatSettings(ctx context.Context, settings *entity.ChatSettings) error {
+	const op = "storage.memory.SaveChatSettings"
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings.UpdatedAt = time.Now()
+
+	stored := *settings
+	s.chatSettings[settings.ChatID] = &stored
+
+	return nil
+}
+
+// GetDailyUsage возвращает расход квоты пользователя за день
+func (s *Storage) GetDailyUsage(ctx context.Context, telegramID int64, day string) (*entity.DailyUsage, error) {
+	const op = "storage.memory.GetDailyUsage"
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if usage, ok := s.usage[usageKey{telegramID, day}]; ok {
+		result := *usage
+		return &result, nil
+	}
+
+	return &entity.DailyUsage{TelegramID: telegramID, Day: day}, nil
+}
+
+// ReserveDailyUsage атомарно увеличивает дневные счётчики пользователя в пределах квоты
+func (s *Storage) ReserveDailyUsage(ctx context.Context, telegramID int64, day string, requests, characters, maxRequests, maxCharacters int) (bool, error) {
+	const op = "storage.memory.ReserveDailyUsage"
+
+	if err := ctx.Err(); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := usageKey{telegramID, day}
+	usage, ok := s.usage[key]
+	if !ok {
+		usage = &entity.DailyUsage{TelegramID: telegramID, Day: day}
+	}
+	if exceeds(usage.Requests+requests, maxRequests) || exceeds(usage.Characters+characters, maxCharacters) {
+		return false, nil
+	}
+
+	usage.Requests += requests
+	usage.Characters += characters
+	s.usage[key] = usage
+
+	return true, nil
+}
+
+// exceeds сообщает, превышает ли value ограничение limit (0 - без ограничения)
+func exceeds(value, limit int) bool {
+	return limit > 0 && value > limit
+}
@@ -0,0 +1,227 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSchemaTooNew возвращается, если БД мигрирована более новой версией приложения
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary supports")
+
+// VersionTable - таблица версий схемы по умолчанию
+const VersionTable = "schema_version"
+
+// Dialect определяет синтаксис плейсхолдеров в служебных запросах
+type Dialect int
+
+const (
+	SQLite Dialect = iota
+	Postgres
+)
+
+// DB - пул или отдельное соединение, через которое применяются миграции.
+// Реализуется *sql.DB и *sql.Conn.
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Migration - одна версия схемы, загруженная из файла NNNN_name.sql
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Load читает миграции из dir в fsys и сортирует их по версии.
+// Имена файлов должны иметь вид 0001_description.sql, версии не должны повторяться.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	const op = "storage.migrate.Load"
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		rawVersion, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(rawVersion)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("%s: invalid migration file name %q", op, entry.Name())
+		}
+		if prev, dup := seen[version]; dup {
+			return nil, fmt.Errorf("%s: duplicate migration version %d (%s, %s)", op, version, prev, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(body)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Pending возвращает миграции, ещё не применённые к БД, ничего в ней не изменяя.
+// БД без таблицы schema_version считается пустой. Если версия схемы
+// в БД выше последней известной миграции, возвращается ErrSchemaTooNew.
+func Pending(ctx context.Context, db DB, dialect Dialect, migrations []Migration) ([]Migration, error) {
+	const op = "storage.migrate.Pending"
+
+	pending, err := pendingIn(ctx, db, dialect, VersionTable, migrations)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pending, nil
+}
+
+func pendingIn(ctx context.Context, db DB, dialect Dialect, table string, migrations []Migration) ([]Migration, error) {
+	current, err := currentVersion(ctx, db, dialect, table)
+	if err != nil {
+		return nil, err
+	}
+
+	if latest := latestVersion(migrations); current > latest {
+		return nil, fmt.Errorf("%w (database version %d, latest known %d)", ErrSchemaTooNew, current, latest)
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > current {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending, nil
+}
+
+// Apply применяет недостающие миграции по порядку, каждую в своей транзакции
+// вместе с записью в schema_version.
+func Apply(ctx context.Context, db DB, dialect Dialect, migrations []Migration) error {
+	const op = "storage.migrate.Apply"
+
+	if err := applyIn(ctx, db, dialect, VersionTable, migrations); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ApplyTable работает как Apply, но ведёт версии в таблице table. Нужна
+// независимым наборам миграций, которые могут оказаться в одной БД
+// (например, кеш проверок в файле основного хранилища).
+func ApplyTable(ctx context.Context, db DB, dialect Dialect, table string, migrations []Migration) error {
+	const op = "storage.migrate.ApplyTable"
+
+	if err := applyIn(ctx, db, dialect, table, migrations); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func applyIn(ctx context.Context, db DB, dialect Dialect, table string, migrations []Migration) error {
+	if err := ensureVersionTable(ctx, db, table); err != nil {
+		return err
+	}
+
+	pending, err := pendingIn(ctx, db, dialect, table, migrations)
+	if err != nil {
+		return err
+	}
+
+	insert := fmt.Sprintf(`INSERT INTO %s (version, name, applied_at) VALUES (?, ?, ?)`, table)
+	if dialect == Postgres {
+		insert = fmt.Sprintf(`INSERT INTO %s (version, name, applied_at) VALUES ($1, $2, $3)`, table)
+	}
+
+	for _, m := range pending {
+		if err := applyOne(ctx, db, insert, m); err != nil {
+			return fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func applyOne(ctx context.Context, db DB, insert string, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, insert, m.Version, m.Name, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func ensureVersionTable(ctx context.Context, db DB, table string) error {
+	query := fmt.Sprintf(`
+    CREATE TABLE IF NOT EXISTS %s (
+        version INTEGER PRIMARY KEY,
+        name TEXT NOT NULL,
+        applied_at TIMESTAMP NOT NULL
+    )
+    `, table)
+	_, err := db.ExecContext(ctx, query)
+	return err
+}
+
+// currentVersion возвращает последнюю применённую версию; 0, если таблицы table нет
+func currentVersion(ctx context.Context, db DB, dialect Dialect, table string) (int, error) {
+	query := `SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?`
+	if dialect == Postgres {
+		query = `SELECT to_regclass($1) IS NOT NULL`
+	}
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, query, table).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	var version sql.NullInt64
+	if err := db.QueryRowContext(ctx, fmt.Sprintf(`SELECT MAX(version) FROM %s`, table)).Scan(&version); err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+func latestVersion(migrations []Migration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
@@ -0,0 +1,129 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var testFS = fstest.MapFS{
+	"migrations/0001_init.sql":  {Data: []byte(`CREATE TABLE items (id INTEGER PRIMARY KEY)`)},
+	"migrations/0002_names.sql": {Data: []byte(`ALTER TABLE items ADD COLUMN name TEXT`)},
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func loadTestMigrations(t *testing.T) []Migration {
+	t.Helper()
+	migrations, err := Load(testFS, "migrations")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return migrations
+}
+
+func TestPendingDoesNotModifyDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrations := loadTestMigrations(t)
+
+	pending, err := Pending(ctx, db, SQLite, migrations)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("pending = %d, want 2", len(pending))
+	}
+
+	var tables int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'`).Scan(&tables); err != nil {
+		t.Fatalf("count tables: %v", err)
+	}
+	if tables != 0 {
+		t.Errorf("Pending created %d tables", tables)
+	}
+}
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrations := loadTestMigrations(t)
+
+	if err := Apply(ctx, db, SQLite, migrations[:1]); err != nil {
+		t.Fatalf("Apply first: %v", err)
+	}
+	pending, err := Pending(ctx, db, SQLite, migrations)
+	if err != nil || len(pending) != 1 || pending[0].Version != 2 {
+		t.Fatalf("Pending = %v, %v; want migration 2", pending, err)
+	}
+
+	if err := Apply(ctx, db, SQLite, migrations); err != nil {
+		t.Fatalf("Apply all: %v", err)
+	}
+	// Повторный запуск ничего не применяет
+	if err := Apply(ctx, db, SQLite, migrations); err != nil {
+		t.Fatalf("Apply again: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO items (name) VALUES ('x')`); err != nil {
+		t.Errorf("schema not migrated: %v", err)
+	}
+
+	if _, err := Pending(ctx, db, SQLite, migrations[:1]); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Pending with older binary = %v, want ErrSchemaTooNew", err)
+	}
+}
+
+func TestApplyTableKeepsVersionsApart(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrations := loadTestMigrations(t)
+
+	cacheMigrations, err := Load(fstest.MapFS{
+		"m/0001_cache.sql": {Data: []byte(`CREATE TABLE cache (key TEXT PRIMARY KEY)`)},
+	}, "m")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if err := Apply(ctx, db, SQLite, migrations); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	// Версия 2 основной схемы не должна выдаваться за версию схемы кеша
+	if err := ApplyTable(ctx, db, SQLite, "cache_schema_version", cacheMigrations); err != nil {
+		t.Fatalf("ApplyTable: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO cache (key) VALUES ('x')`); err != nil {
+		t.Errorf("cache schema not migrated: %v", err)
+	}
+
+	pending, err := Pending(ctx, db, SQLite, migrations)
+	if err != nil || len(pending) != 0 {
+		t.Fatalf("Pending after ApplyTable = %v, %v; want none", pending, err)
+	}
+}
+
+func TestLoadRejectsInvalidNames(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"no version": {"m/init.sql": {}},
+		"zero":       {"m/0000_init.sql": {}},
+		"duplicate":  {"m/0001_a.sql": {}, "m/0001_b.sql": {}},
+	}
+	for name, fsys := range tests {
+		if _, err := Load(fsys, "m"); err == nil {
+			t.Errorf("%s: Load succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+CREATE TABLE user_settings (
+    telegram_id BIGINT PRIMARY KEY,
+    output_format TEXT NOT NULL,
+    show_explanations BOOLEAN NOT NULL,
+    diff_view BOOLEAN NOT NULL,
+    strictness TEXT NOT NULL,
+    language TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
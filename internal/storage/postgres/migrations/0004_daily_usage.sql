@@ -0,0 +1,7 @@
+CREATE TABLE daily_usage (
+    telegram_id BIGINT NOT NULL,
+    day TEXT NOT NULL,
+    requests INTEGER NOT NULL DEFAULT 0,
+    characters BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (telegram_id, day)
+);
@@ -0,0 +1,5 @@
+CREATE TABLE chat_settings (
+    chat_id BIGINT PRIMARY KEY,
+    auto_check BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
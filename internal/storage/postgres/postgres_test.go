@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"spell_bot/internal/storage"
+	"spell_bot/internal/storage/storagetest"
+)
+
+// testDSNEnv - переменная окружения с DSN тестовой БД; без неё тесты пропускаются
+const testDSNEnv = "TEST_POSTGRES_DSN"
+
+var schemaSeq atomic.Int64
+
+// newTestStorage создаёт хранилище в отдельной схеме, которая удаляется после теста.
+// Пул из одного соединения проверяет, что миграции не требуют второго.
+func newTestStorage(t *testing.T) storage.Storage {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	admin, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("storagetest_%d_%d", os.Getpid(), schemaSeq.Add(1))
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`); err != nil {
+			t.Errorf("drop schema: %v", err)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var s *Storage
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s, err = NewStorage(withSearchPath(dsn, schema), PoolConfig{MaxOpenConns: 1, MaxIdleConns: 1})
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("NewStorage did not finish: migrations are blocked on the connection pool")
+	}
+	if err != nil {
+		t.Fatalf("NewStorage: %v", err)
+	}
+
+	return s
+}
+
+// withSearchPath направляет все запросы соединения в схему schema
+func withSearchPath(dsn, schema string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return strings.TrimSpace(dsn) + " search_path=" + schema
+}
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, newTestStorage)
+}
+
+func TestPendingMigrations(t *testing.T) {
+	s := newTestStorage(t).(*Storage)
+	defer s.Close()
+
+	var schema string
+	if err := s.db.QueryRow(`SELECT current_schema()`).Scan(&schema); err != nil {
+		t.Fatalf("current_schema: %v", err)
+	}
+
+	pending, err := PendingMigrations(context.Background(), withSearchPath(os.Getenv(testDSNEnv), schema))
+	if err != nil {
+		t.Fatalf("PendingMigrations: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("pending after NewStorage = %d, want 0", len(pending))
+	}
+}
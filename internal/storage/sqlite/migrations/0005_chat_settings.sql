@@ -0,0 +1,5 @@
+CREATE TABLE chat_settings (
+    chat_id INTEGER PRIMARY KEY,
+    auto_check BOOLEAN NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -3,13 +3,22 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"spell_bot/internal/entity"
+	"spell_bot/internal/storage"
+	"spell_bot/internal/storage/migrate"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
 type Storage struct {
 	db *sql.DB
 }
@@ -30,38 +39,63 @@ func NewStorage(path string) (*Storage, error) {
 	}
 
 	if err := s.init(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	return s, nil
 }
 
+// init приводит схему БД к последней версии
 func (s *Storage) init() error {
 	const op = "storage.sqlite.init"
 
-	query := `
-    CREATE TABLE IF NOT EXISTS users (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        telegram_id INTEGER NOT NULL UNIQUE,
-        chat_id INTEGER NOT NULL UNIQUE,
-        username TEXT,
-        first_name TEXT,
-        last_name TEXT,
-        created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-        updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
-    );
-
-    CREATE INDEX IF NOT EXISTS idx_users_telegram_id ON users(telegram_id);
-    CREATE INDEX IF NOT EXISTS idx_users_chat_id ON users(chat_id);
-    `
+	migrations, err := migrate.Load(migrationsFS, "migrations")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 
-	if _, err := s.db.Exec(query); err != nil {
+	if err := migrate.Apply(context.Background(), s.db, migrate.SQLite, migrations); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	return nil
 }
 
+// PendingMigrations возвращает миграции, которые будут применены к БД по path
+// при следующем запуске, не изменяя схему
+func PendingMigrations(ctx context.Context, path string) ([]migrate.Migration, error) {
+	const op = "storage.sqlite.PendingMigrations"
+
+	migrations, err := migrate.Load(migrationsFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Открытие несуществующего файла создало бы пустую БД
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return migrations, nil
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer db.Close()
+
+	pending, err := migrate.Pending(ctx, db, migrate.SQLite, migrations)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pending, nil
+}
+
+// Ping проверяет соединение с БД
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
 func (s *Storage) Close() error {
 	return s.db.Close()
 }
@@ -106,3 +140,321 @@ func (s *Storage) SaveUser(ctx context.Context, user *entity.User) error {
 
 	return nil
 }
+
+// SaveCheck сохраняет результат проверки текста
+func (s *Storage) SaveCheck(ctx context.Context, check *entity.Check) error {
+	const op = "storage.sqlite.SaveCheck"
+
+	query := `
+    INSERT INTO checks (
+        telegram_id, chat_id, original_text, corrected_text, explanation, model,
+        latency_ms, prompt_tokens, completion_tokens, total_tokens, status, error, created_at
+    )
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    RETURNING id
+    `
+
+	if check.CreatedAt.IsZero() {
+		check.CreatedAt = time.Now()
+	}
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		check.TelegramID,
+		check.ChatID,
+		check.OriginalText,
+		check.CorrectedText,
+		check.Explanation,
+		check.Model,
+		check.Latency.Milliseconds(),
+		check.PromptTokens,
+		check.CompletionTokens,
+		check.TotalTokens,
+		check.Status,
+		check.Error,
+		check.CreatedAt,
+	).Scan(&check.ID)
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+const checkColumns = `
+    id, telegram_id, chat_id, original_text, corrected_text, explanation, model,
+    latency_ms, prompt_tokens, completion_tokens, total_tokens, status, error, created_at
+`
+
+// GetCheck возвращает проверку по ID
+func (s *Storage) GetCheck(ctx context.Context, id int64) (*entity.Check, error) {
+	const op = "storage.sqlite.GetCheck"
+
+	row := s.db.QueryRowContext(ctx, `SELECT `+checkColumns+` FROM checks WHERE id = ?`, id)
+
+	check, err := scanCheck(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return check, nil
+}
+
+// ListChecks возвращает проверки пользователя от новых к старым
+func (s *Storage) ListChecks(ctx context.Context, telegramID int64, limit, offset int) ([]*entity.Check, error) {
+	const op = "storage.sqlite.ListChecks"
+
+	query := `SELECT ` + checkColumns + `
+    FROM checks
+    WHERE telegram_id = ?
+    ORDER BY created_at DESC, id DESC
+    LIMIT ? OFFSET ?
+    `
+
+	rows, err := s.db.QueryContext(ctx, query, telegramID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var checks []*entity.Check
+	for rows.Next() {
+		check, err := scanCheck(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		checks = append(checks, check)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return checks, nil
+}
+
+// CountChecks возвращает число проверок пользователя
+func (s *Storage) CountChecks(ctx context.Context, telegramID int64) (int, error) {
+	const op = "storage.sqlite.CountChecks"
+
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM checks WHERE telegram_id = ?`, telegramID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCheck(row scanner) (*entity.Check, error) {
+	var (
+		check     entity.Check
+		latencyMs int64
+	)
+
+	err := row.Scan(
+		&check.ID,
+		&check.TelegramID,
+		&check.ChatID,
+		&check.OriginalText,
+		&check.CorrectedText,
+		&check.Explanation,
+		&check.Model,
+		&latencyMs,
+		&check.PromptTokens,
+		&check.CompletionTokens,
+		&check.TotalTokens,
+		&check.Status,
+		&check.Error,
+		&check.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	check.Latency = time.Duration(latencyMs) * time.Millisecond
+
+	return &check, nil
+}
+
+// GetUserSettings возвращает настройки пользователя
+func (s *Storage) GetUserSettings(ctx context.Context, telegramID int64) (*entity.UserSettings, error) {
+	const op = "storage.sqlite.GetUserSettings"
+
+	query := `
+    SELECT telegram_id, output_format, show_explanations, diff_view, strictness, language, updated_at
+    FROM user_settings
+    WHERE telegram_id = ?
+    `
+
+	var settings entity.UserSettings
+	err := s.db.QueryRowContext(ctx, query, telegramID).Scan(
+		&settings.TelegramID,
+		&settings.OutputFormat,
+		&settings.ShowExplanations,
+		&settings.DiffView,
+		&settings.Strictness,
+		&settings.Language,
+		&settings.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &settings, nil
+}
+
+// SaveUserSettings сохраняет настройки пользователя (UPSERT)
+func (s *Storage) SaveUserSettings(ctx context.Context, settings *entity.UserSettings) error {
+	const op = "storage.sqlite.SaveUserSettings"
+
+	query := `
+    INSERT INTO user_settings (telegram_id, output_format, show_explanations, diff_view, strictness, language, updated_at)
+    VALUES (?, ?, ?, ?, ?, ?, ?)
+    ON CONFLICT (telegram_id) DO UPDATE SET
+        output_format = excluded.output_format,
+        show_explanations = excluded.show_explanations,
+        diff_view = excluded.diff_view,
+        strictness = excluded.strictness,
+        language = excluded.language,
+        updated_at = excluded.updated_at
+    `
+
+	settings.UpdatedAt = time.Now()
+
+	_, err := s.db.ExecContext(
+		ctx,
+		query,
+		settings.TelegramID,
+		settings.OutputFormat,
+		settings.ShowExplanations,
+		settings.DiffView,
+		settings.Strictness,
+		settings.Language,
+		settings.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetChatSettings возвращает настройки группового чата
+func (s *Storage) GetChatSettings(ctx context.Context, chatID int64) (*entity.ChatSettings, error) {
+	const op = "storage.sqlite.GetChatSettings"
+
+	query := `
+    SELECT chat_id, auto_check, updated_at
+    FROM chat_settings
+    WHERE chat_id = ?
+    `
+
+	var settings entity.ChatSettings
+	err := s.db.QueryRowContext(ctx, query, chatID).Scan(
+		&settings.ChatID,
+		&settings.AutoCheck,
+		&settings.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &settings, nil
+}
+
+// SaveChatSettings сохраняет настройки группового чата (UPSERT)
+func (s *Storage) SaveChatSettings(ctx context.Context, settings *entity.ChatSettings) error {
+	const op = "storage.sqlite.SaveChatSettings"
+
+	query := `
+    INSERT INTO chat_settings (chat_id, auto_check, updated_at)
+    VALUES (?, ?, ?)
+    ON CONFLICT (chat_id) DO UPDATE SET
+        auto_check = excluded.auto_check,
+        updated_at = excluded.updated_at
+    `
+
+	settings.UpdatedAt = time.Now()
+
+	_, err := s.db.ExecContext(ctx, query, settings.ChatID, settings.AutoCheck, settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetDailyUsage возвращает расход квоты пользователя за день
+func (s *Storage) GetDailyUsage(ctx context.Context, telegramID int64, day string) (*entity.DailyUsage, error) {
+	const op = "storage.sqlite.GetDailyUsage"
+
+	query := `
+    SELECT requests, characters
+    FROM daily_usage
+    WHERE telegram_id = ? AND day = ?
+    `
+
+	usage := &entity.DailyUsage{TelegramID: telegramID, Day: day}
+	err := s.db.QueryRowContext(ctx, query, telegramID, day).Scan(&usage.Requests, &usage.Characters)
+	if errors.Is(err, sql.ErrNoRows) {
+		return usage, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return usage, nil
+}
+
+// ReserveDailyUsage атомарно увеличивает дневные счётчики пользователя в пределах квоты
+func (s *Storage) ReserveDailyUsage(ctx context.Context, telegramID int64, day string, requests, characters, maxRequests, maxCharacters int) (bool, error) {
+	const op = "storage.sqlite.ReserveDailyUsage"
+
+	// Первая запись дня проверяется здесь, последующие - условием ON CONFLICT
+	if exceeds(requests, maxRequests) || exceeds(characters, maxCharacters) {
+		return false, nil
+	}
+
+	query := `
+    INSERT INTO daily_usage (telegram_id, day, requests, characters)
+    VALUES (?, ?, ?, ?)
+    ON CONFLICT (telegram_id, day) DO UPDATE SET
+        requests = daily_usage.requests + excluded.requests,
+        characters = daily_usage.characters + excluded.characters
+    WHERE (? = 0 OR daily_usage.requests + excluded.requests <= ?)
+        AND (? = 0 OR daily_usage.characters + excluded.characters <= ?)
+    `
+
+	result, err := s.db.ExecContext(ctx, query,
+		telegramID, day, requests, characters,
+		maxRequests, maxRequests, maxCharacters, maxCharacters,
+	)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return affected > 0, nil
+}
+
+// exceeds сообщает, превышает ли value ограничение limit (0 - без ограничения)
+func exceeds(value, limit int) bool {
+	return limit > 0 && value > limit
+}
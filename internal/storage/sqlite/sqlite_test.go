@@ -0,0 +1,19 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"spell_bot/internal/storage"
+	"spell_bot/internal/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		s, err := NewStorage(filepath.Join(t.TempDir(), "spell_bot.db"))
+		if err != nil {
+			t.Fatalf("NewStorage: %v", err)
+		}
+		return s
+	})
+}
@@ -2,11 +2,44 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"spell_bot/internal/entity"
 )
 
+// ErrNotFound возвращается, когда запрошенная запись отсутствует
+var ErrNotFound = errors.New("not found")
+
 type Storage interface {
 	SaveUser(ctx context.Context, user *entity.User) error
+
+	// SaveCheck сохраняет результат проверки текста и заполняет check.ID
+	SaveCheck(ctx context.Context, check *entity.Check) error
+	// GetCheck возвращает проверку по ID или ErrNotFound
+	GetCheck(ctx context.Context, id int64) (*entity.Check, error)
+	// ListChecks возвращает проверки пользователя, начиная с самых новых
+	ListChecks(ctx context.Context, telegramID int64, limit, offset int) ([]*entity.Check, error)
+	// CountChecks возвращает число проверок пользователя
+	CountChecks(ctx context.Context, telegramID int64) (int, error)
+
+	// GetUserSettings возвращает настройки пользователя или ErrNotFound, если он их не менял
+	GetUserSettings(ctx context.Context, telegramID int64) (*entity.UserSettings, error)
+	// SaveUserSettings сохраняет настройки пользователя (UPSERT по telegram_id)
+	SaveUserSettings(ctx context.Context, settings *entity.UserSettings) error
+
+	// GetChatSettings возвращает настройки группового чата или ErrNotFound, если их не меняли
+	GetChatSettings(ctx context.Context, chatID int64) (*entity.ChatSettings, error)
+	// SaveChatSettings сохраняет настройки группового чата (UPSERT по chat_id)
+	SaveChatSettings(ctx context.Context, settings *entity.ChatSettings) error
+
+	// GetDailyUsage возвращает расход квоты пользователя за день (нулевой, если записей нет)
+	GetDailyUsage(ctx context.Context, telegramID int64, day string) (*entity.DailyUsage, error)
+	// ReserveDailyUsage атомарно увеличивает дневные счётчики пользователя, если после
+	// этого они не превысят maxRequests и maxCharacters (0 - без ограничения);
+	// false, если квота не позволяет, счётчики тогда не меняются
+	ReserveDailyUsage(ctx context.Context, telegramID int64, day string, requests, characters, maxRequests, maxCharacters int) (bool, error)
+
+	// Ping проверяет доступность БД
+	Ping(ctx context.Context) error
 	// Close закрывает соединение с БД
 	Close() error
 }
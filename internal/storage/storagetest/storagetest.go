@@ -0,0 +1,377 @@
+// Package storagetest содержит общий набор проверок, которому должна
+// соответствовать каждая реализация storage.Storage (sqlite, memory, postgres).
+//
+// Использование в тестах бэкенда:
+//
+//	func TestConformance(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) storage.Storage {
+//			return memory.NewStorage()
+//		})
+//	}
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"spell_bot/internal/entity"
+	"spell_bot/internal/storage"
+)
+
+// Factory создаёт пустое хранилище для одного подтеста
+type Factory func(t *testing.T) storage.Storage
+
+// Run запускает все проверки соответствия для хранилищ, созданных newStorage
+func Run(t *testing.T, newStorage Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s storage.Storage)
+	}{
+		{"Ping", testPing},
+		{"SaveUserAssignsID", testSaveUserAssignsID},
+		{"SaveUserUpsertsByChatID", testSaveUserUpsertsByChatID},
+		{"SaveUserDistinctChats", testSaveUserDistinctChats},
+		{"SaveUserTelegramIDUnique", testSaveUserTelegramIDUnique},
+		{"SaveAndGetCheck", testSaveAndGetCheck},
+		{"GetCheckNotFound", testGetCheckNotFound},
+		{"ListChecksOrderAndPagination", testListChecksOrderAndPagination},
+		{"ChecksIsolatedByUser", testChecksIsolatedByUser},
+		{"UserSettingsNotFound", testUserSettingsNotFound},
+		{"UserSettingsUpsert", testUserSettingsUpsert},
+		{"ChatSettingsNotFound", testChatSettingsNotFound},
+		{"ChatSettingsUpsert", testChatSettingsUpsert},
+		{"DailyUsageAccumulates", testDailyUsageAccumulates},
+		{"DailyUsageReserveLimits", testDailyUsageReserveLimits},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStorage(t)
+			t.Cleanup(func() {
+				if err := s.Close(); err != nil {
+					t.Errorf("Close: %v", err)
+				}
+			})
+			tt.fn(t, s)
+		})
+	}
+}
+
+func testPing(t *testing.T, s storage.Storage) {
+	if err := s.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+}
+
+func testSaveUserAssignsID(t *testing.T, s storage.Storage) {
+	user := entity.NewUser(1, 100, "alice", "Alice", "A")
+	if err := s.SaveUser(context.Background(), user); err != nil {
+		t.Fatalf("SaveUser: %v", err)
+	}
+	if user.ID <= 0 {
+		t.Fatalf("SaveUser: expected positive ID, got %d", user.ID)
+	}
+}
+
+func testSaveUserUpsertsByChatID(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	first := entity.NewUser(1, 100, "alice", "Alice", "A")
+	if err := s.SaveUser(ctx, first); err != nil {
+		t.Fatalf("SaveUser: %v", err)
+	}
+
+	updated := entity.NewUser(1, 100, "alice_new", "Alicia", "B")
+	if err := s.SaveUser(ctx, updated); err != nil {
+		t.Fatalf("SaveUser (upsert): %v", err)
+	}
+	if updated.ID != first.ID {
+		t.Fatalf("SaveUser (upsert): expected ID %d to be kept, got %d", first.ID, updated.ID)
+	}
+}
+
+func testSaveUserDistinctChats(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	a := entity.NewUser(1, 100, "alice", "", "")
+	b := entity.NewUser(2, 200, "bob", "", "")
+	if err := s.SaveUser(ctx, a); err != nil {
+		t.Fatalf("SaveUser(a): %v", err)
+	}
+	if err := s.SaveUser(ctx, b); err != nil {
+		t.Fatalf("SaveUser(b): %v", err)
+	}
+	if a.ID == b.ID {
+		t.Fatalf("SaveUser: expected distinct IDs, both are %d", a.ID)
+	}
+}
+
+func testSaveUserTelegramIDUnique(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	if err := s.SaveUser(ctx, entity.NewUser(1, 100, "alice", "", "")); err != nil {
+		t.Fatalf("SaveUser: %v", err)
+	}
+	if err := s.SaveUser(ctx, entity.NewUser(1, 200, "alice", "", "")); err == nil {
+		t.Fatalf("SaveUser: expected error for telegram_id already bound to another chat")
+	}
+}
+
+func testSaveAndGetCheck(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	check := newCheck(1, time.Now().Add(-time.Hour))
+	check.CorrectedText = "Привет, мир!"
+	check.Explanation = "запятая"
+	check.Model = "test-model"
+	check.Latency = 1500 * time.Millisecond
+	check.PromptTokens = 10
+	check.CompletionTokens = 5
+	check.TotalTokens = 15
+
+	if err := s.SaveCheck(ctx, check); err != nil {
+		t.Fatalf("SaveCheck: %v", err)
+	}
+	if check.ID <= 0 {
+		t.Fatalf("SaveCheck: expected positive ID, got %d", check.ID)
+	}
+
+	got, err := s.GetCheck(ctx, check.ID)
+	if err != nil {
+		t.Fatalf("GetCheck: %v", err)
+	}
+
+	if got.TelegramID != check.TelegramID || got.ChatID != check.ChatID ||
+		got.OriginalText != check.OriginalText || got.CorrectedText != check.CorrectedText ||
+		got.Explanation != check.Explanation || got.Model != check.Model ||
+		got.Latency != check.Latency || got.Status != check.Status || got.Error != check.Error ||
+		got.PromptTokens != check.PromptTokens || got.CompletionTokens != check.CompletionTokens ||
+		got.TotalTokens != check.TotalTokens {
+		t.Fatalf("GetCheck: got %+v, want %+v", got, check)
+	}
+	if d := got.CreatedAt.Sub(check.CreatedAt); d > time.Second || d < -time.Second {
+		t.Fatalf("GetCheck: CreatedAt %v, want %v", got.CreatedAt, check.CreatedAt)
+	}
+}
+
+func testGetCheckNotFound(t *testing.T, s storage.Storage) {
+	_, err := s.GetCheck(context.Background(), 424242)
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("GetCheck: expected storage.ErrNotFound, got %v", err)
+	}
+}
+
+func testListChecksOrderAndPagination(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour)
+
+	var ids []int64
+	for i := range 5 {
+		check := newCheck(1, base.Add(time.Duration(i)*time.Minute))
+		if err := s.SaveCheck(ctx, check); err != nil {
+			t.Fatalf("SaveCheck: %v", err)
+		}
+		ids = append(ids, check.ID)
+	}
+
+	count, err := s.CountChecks(ctx, 1)
+	if err != nil {
+		t.Fatalf("CountChecks: %v", err)
+	}
+	if count != 5 {
+		t.Fatalf("CountChecks: got %d, want 5", count)
+	}
+
+	page1, err := s.ListChecks(ctx, 1, 2, 0)
+	if err != nil {
+		t.Fatalf("ListChecks: %v", err)
+	}
+	page3, err := s.ListChecks(ctx, 1, 2, 4)
+	if err != nil {
+		t.Fatalf("ListChecks: %v", err)
+	}
+
+	// Самые новые проверки идут первыми
+	if len(page1) != 2 || page1[0].ID != ids[4] || page1[1].ID != ids[3] {
+		t.Fatalf("ListChecks page 1: got %v, want [%d %d]", checkIDs(page1), ids[4], ids[3])
+	}
+	if len(page3) != 1 || page3[0].ID != ids[0] {
+		t.Fatalf("ListChecks page 3: got %v, want [%d]", checkIDs(page3), ids[0])
+	}
+
+	empty, err := s.ListChecks(ctx, 1, 2, 10)
+	if err != nil {
+		t.Fatalf("ListChecks: %v", err)
+	}
+	if len(empty) != 0 {
+		t.Fatalf("ListChecks past the end: got %v, want none", checkIDs(empty))
+	}
+}
+
+func testChecksIsolatedByUser(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	if err := s.SaveCheck(ctx, newCheck(1, time.Now())); err != nil {
+		t.Fatalf("SaveCheck: %v", err)
+	}
+	if err := s.SaveCheck(ctx, newCheck(2, time.Now())); err != nil {
+		t.Fatalf("SaveCheck: %v", err)
+	}
+
+	checks, err := s.ListChecks(ctx, 2, 10, 0)
+	if err != nil {
+		t.Fatalf("ListChecks: %v", err)
+	}
+	if len(checks) != 1 || checks[0].TelegramID != 2 {
+		t.Fatalf("ListChecks: expected only checks of user 2, got %+v", checks)
+	}
+
+	count, err := s.CountChecks(ctx, 3)
+	if err != nil {
+		t.Fatalf("CountChecks: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("CountChecks for unknown user: got %d, want 0", count)
+	}
+}
+
+func testUserSettingsNotFound(t *testing.T, s storage.Storage) {
+	_, err := s.GetUserSettings(context.Background(), 1)
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("GetUserSettings: expected storage.ErrNotFound, got %v", err)
+	}
+}
+
+func testUserSettingsUpsert(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	settings := entity.DefaultUserSettings(1, true)
+	if err := s.SaveUserSettings(ctx, settings); err != nil {
+		t.Fatalf("SaveUserSettings: %v", err)
+	}
+
+	settings.OutputFormat = entity.OutputFormatPlain
+	settings.ShowExplanations = false
+	settings.DiffView = false
+	settings.Strictness = entity.StrictnessStrict
+	settings.Language = entity.LanguageEnglish
+	if err := s.SaveUserSettings(ctx, settings); err != nil {
+		t.Fatalf("SaveUserSettings (upsert): %v", err)
+	}
+
+	got, err := s.GetUserSettings(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetUserSettings: %v", err)
+	}
+	if got.TelegramID != 1 || got.OutputFormat != settings.OutputFormat ||
+		got.ShowExplanations != settings.ShowExplanations || got.DiffView != settings.DiffView ||
+		got.Strictness != settings.Strictness || got.Language != settings.Language {
+		t.Fatalf("GetUserSettings: got %+v, want %+v", got, settings)
+	}
+}
+
+func testChatSettingsNotFound(t *testing.T, s storage.Storage) {
+	_, err := s.GetChatSettings(context.Background(), -100)
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("GetChatSettings: expected storage.ErrNotFound, got %v", err)
+	}
+}
+
+func testChatSettingsUpsert(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	settings := entity.DefaultChatSettings(-100)
+	if err := s.SaveChatSettings(ctx, settings); err != nil {
+		t.Fatalf("SaveChatSettings: %v", err)
+	}
+
+	settings.AutoCheck = true
+	if err := s.SaveChatSettings(ctx, settings); err != nil {
+		t.Fatalf("SaveChatSettings (upsert): %v", err)
+	}
+
+	got, err := s.GetChatSettings(ctx, -100)
+	if err != nil {
+		t.Fatalf("GetChatSettings: %v", err)
+	}
+	if got.ChatID != -100 || !got.AutoCheck {
+		t.Fatalf("GetChatSettings: got %+v, want %+v", got, settings)
+	}
+}
+
+func testDailyUsageAccumulates(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	empty, err := s.GetDailyUsage(ctx, 1, "2024-01-01")
+	if err != nil {
+		t.Fatalf("GetDailyUsage: %v", err)
+	}
+	if empty.Requests != 0 || empty.Characters != 0 {
+		t.Fatalf("GetDailyUsage without records: got %+v, want zero usage", empty)
+	}
+
+	for _, chars := range []int{100, 250} {
+		reserveDailyUsage(t, s, "2024-01-01", 1, chars, 0, 0, true)
+	}
+	reserveDailyUsage(t, s, "2024-01-02", 1, 5, 0, 0, true)
+
+	usage, err := s.GetDailyUsage(ctx, 1, "2024-01-01")
+	if err != nil {
+		t.Fatalf("GetDailyUsage: %v", err)
+	}
+	if usage.Requests != 2 || usage.Characters != 350 {
+		t.Fatalf("GetDailyUsage: got %+v, want 2 requests and 350 characters", usage)
+	}
+}
+
+func testDailyUsageReserveLimits(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	// Один запрос больше квоты не проходит даже в пустой день
+	reserveDailyUsage(t, s, "2024-01-01", 1, 101, 2, 100, false)
+
+	reserveDailyUsage(t, s, "2024-01-01", 1, 60, 2, 100, true)
+	reserveDailyUsage(t, s, "2024-01-01", 1, 41, 2, 100, false)
+	reserveDailyUsage(t, s, "2024-01-01", 1, 40, 2, 100, true)
+	reserveDailyUsage(t, s, "2024-01-01", 1, 0, 2, 100, false)
+	// Нулевые ограничения отключают проверку
+	reserveDailyUsage(t, s, "2024-01-01", 1, 10, 0, 0, true)
+
+	usage, err := s.GetDailyUsage(ctx, 1, "2024-01-01")
+	if err != nil {
+		t.Fatalf("GetDailyUsage: %v", err)
+	}
+	if usage.Requests != 3 || usage.Characters != 110 {
+		t.Fatalf("GetDailyUsage: got %+v, want 3 requests and 110 characters", usage)
+	}
+}
+
+func reserveDailyUsage(t *testing.T, s storage.Storage, day string, requests, characters, maxRequests, maxCharacters int, want bool) {
+	t.Helper()
+
+	got, err := s.ReserveDailyUsage(context.Background(), 1, day, requests, characters, maxRequests, maxCharacters)
+	if err != nil {
+		t.Fatalf("ReserveDailyUsage: %v", err)
+	}
+	if got != want {
+		t.Fatalf("ReserveDailyUsage(%s, %d, %d, max %d/%d): got %v, want %v",
+			day, requests, characters, maxRequests, maxCharacters, got, want)
+	}
+}
+
+func newCheck(telegramID int64, createdAt time.Time) *entity.Check {
+	check := entity.NewCheck(telegramID, telegramID*100, "Превет мир")
+	check.Status = entity.CheckStatusOK
+	check.CreatedAt = createdAt
+	return check
+}
+
+func checkIDs(checks []*entity.Check) []int64 {
+	ids := make([]int64, len(checks))
+	for i, c := range checks {
+		ids[i] = c.ID
+	}
+	return ids
+}
@@ -0,0 +1,159 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed возвращается при попытке добавить задачу в остановленный пул
+var ErrClosed = errors.New("worker pool is closed")
+
+// Task - единица работы. ctx отменяется, только если пул не успел
+// завершить задачи за время, отведённое на Drain.
+type Task func(ctx context.Context)
+
+// Stats - текущая загрузка пула
+type Stats struct {
+	Queued   int64 // задачи, ожидающие в очередях
+	InFlight int64 // выполняющиеся задачи
+	Capacity int   // суммарная ёмкость очередей
+}
+
+// Pool - ограниченный пул воркеров. Задачи с одинаковым ключом попадают
+// в одну очередь и выполняются строго последовательно в порядке добавления;
+// при заполненной очереди Submit блокируется (backpressure).
+type Pool struct {
+	queues []chan Task
+	logger *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu         sync.RWMutex
+	closed     bool
+	done       chan struct{}  // закрывается при остановке пула
+	submitters sync.WaitGroup // вызовы Submit, ожидающие места в очереди
+
+	queued   atomic.Int64
+	inFlight atomic.Int64
+}
+
+// New запускает workers воркеров с общей ёмкостью очередей queueSize
+func New(workers, queueSize int, logger *slog.Logger) *Pool {
+	workers = max(workers, 1)
+	perWorker := max(queueSize/workers, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		queues: make([]chan Task, workers),
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	for i := range p.queues {
+		p.queues[i] = make(chan Task, perWorker)
+		p.wg.Add(1)
+		go p.work(p.queues[i])
+	}
+
+	return p
+}
+
+// Submit ставит задачу в очередь, выбранную по key. Блокируется, пока
+// в очереди нет места, ctx не отменён или пул не остановлен.
+func (p *Pool) Submit(ctx context.Context, key int64, task Task) error {
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return ErrClosed
+	}
+	// Блокировка не удерживается во время ожидания места в очереди. Drain сначала
+	// закрывает done и закрывает очереди только после выхода всех Submit,
+	// поэтому отправки в закрытый канал не бывает.
+	p.submitters.Add(1)
+	p.mu.RUnlock()
+	defer p.submitters.Done()
+
+	queue := p.queues[uint64(key)%uint64(len(p.queues))]
+
+	p.queued.Add(1)
+	select {
+	case queue <- task:
+		return nil
+	case <-ctx.Done():
+		p.queued.Add(-1)
+		return ctx.Err()
+	case <-p.done:
+		p.queued.Add(-1)
+		return ErrClosed
+	}
+}
+
+// Stats возвращает текущую загрузку пула
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Queued:   p.queued.Load(),
+		InFlight: p.inFlight.Load(),
+		Capacity: len(p.queues) * cap(p.queues[0]),
+	}
+}
+
+// Drain перестаёт принимать задачи и ждёт выполнения уже поставленных.
+// Если ctx истекает раньше, контекст задач отменяется и возвращается ошибка.
+func (p *Pool) Drain(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.done)
+		p.submitters.Wait()
+		for _, q := range p.queues {
+			close(q)
+		}
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		stats := p.Stats()
+		return fmt.Errorf("drain interrupted with %d queued and %d in-flight tasks: %w", stats.Queued, stats.InFlight, ctx.Err())
+	}
+}
+
+func (p *Pool) work(queue <-chan Task) {
+	defer p.wg.Done()
+
+	for task := range queue {
+		p.queued.Add(-1)
+		p.run(task)
+	}
+}
+
+func (p *Pool) run(task Task) {
+	p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+
+	defer func() {
+		if r := recover(); r != nil {
+			p.logger.Error("worker task panicked", "panic", r)
+		}
+	}()
+
+	task(p.ctx)
+}
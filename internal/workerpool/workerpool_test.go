@@ -0,0 +1,128 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestSubmitKeepsOrderPerKey(t *testing.T) {
+	p := New(4, 16, testLogger)
+
+	const keys, tasks = 8, 50
+
+	var (
+		mu  sync.Mutex
+		got = make(map[int64][]int)
+	)
+	for i := 0; i < tasks; i++ {
+		for key := int64(0); key < keys; key++ {
+			err := p.Submit(context.Background(), key, func(context.Context) {
+				mu.Lock()
+				got[key] = append(got[key], i)
+				mu.Unlock()
+			})
+			if err != nil {
+				t.Fatalf("Submit: %v", err)
+			}
+		}
+	}
+
+	if err := p.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+
+	for key := int64(0); key < keys; key++ {
+		if len(got[key]) != tasks {
+			t.Fatalf("key %d: ran %d tasks, want %d", key, len(got[key]), tasks)
+		}
+		for i, n := range got[key] {
+			if n != i {
+				t.Fatalf("key %d: task %d ran at position %d", key, n, i)
+			}
+		}
+	}
+	if stats := p.Stats(); stats.Queued != 0 || stats.InFlight != 0 {
+		t.Fatalf("Stats after Drain = %+v, want empty pool", stats)
+	}
+}
+
+func TestDrainDeadlineWithBlockedSubmit(t *testing.T) {
+	p := New(1, 1, testLogger)
+
+	started := make(chan struct{})
+	// Первая задача занимает воркер до отмены контекста пула, вторая заполняет очередь
+	if err := p.Submit(context.Background(), 0, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+	}); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	<-started
+	if err := p.Submit(context.Background(), 0, func(context.Context) {}); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	submitErr := make(chan error, 1)
+	go func() {
+		submitErr <- p.Submit(context.Background(), 0, func(context.Context) {})
+	}()
+	// Даём третьему Submit заблокироваться на полной очереди
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	drained := make(chan error, 1)
+	go func() { drained <- p.Drain(ctx) }()
+
+	select {
+	case err := <-drained:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Drain: err = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Drain ignored its deadline while Submit was blocked")
+	}
+
+	select {
+	case err := <-submitErr:
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("blocked Submit: err = %v, want %v", err, ErrClosed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("blocked Submit did not return after Drain")
+	}
+
+	if err := p.Submit(context.Background(), 0, func(context.Context) {}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Submit after Drain: err = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestPanickingTaskDoesNotStopWorker(t *testing.T) {
+	p := New(1, 4, testLogger)
+
+	ran := false
+	if err := p.Submit(context.Background(), 0, func(context.Context) { panic("boom") }); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if err := p.Submit(context.Background(), 0, func(context.Context) { ran = true }); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	if err := p.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+	if !ran {
+		t.Fatal("task after a panicking one did not run")
+	}
+	if stats := p.Stats(); stats.InFlight != 0 {
+		t.Fatalf("InFlight after panic = %d, want 0", stats.InFlight)
+	}
+}